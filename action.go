@@ -0,0 +1,51 @@
+package gojsonforms
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+	"github.com/TobiEiss/go-jsonforms/models"
+)
+
+// ActionField is the form field the name of the pressed action is submitted
+// in.
+const ActionField = form.ActionField
+
+type actionKey struct{}
+
+// ActionFromContext returns the action a form was submitted with, set by the
+// Handler for its SubmitFunc.
+func ActionFromContext(ctx context.Context) (models.Action, bool) {
+	action, ok := ctx.Value(actionKey{}).(models.Action)
+	return action, ok
+}
+
+func contextWithAction(ctx context.Context, action models.Action) context.Context {
+	return context.WithValue(ctx, actionKey{}, action)
+}
+
+// defaultActions is the single submit button of forms without actions, with
+// the confirmation of the builder if it has a button text.
+func defaultActions(c models.Confirmation) []models.Action {
+	if c.ButtonText == "" {
+		return []models.Action{{Label: "OK"}}
+	}
+	return []models.Action{{Label: c.ButtonText, Confirmation: &c}}
+}
+
+// Action returns the action submitted values were submitted with, the first
+// one if they name none. Unknown names are reported as ErrInvalidData.
+func (f *Form) Action(values url.Values) (models.Action, error) {
+	name := values.Get(ActionField)
+	if name == "" {
+		return f.page.Actions[0], nil
+	}
+	for _, action := range f.page.Actions {
+		if action.Name == name {
+			return action, nil
+		}
+	}
+	return models.Action{}, &FormError{Stage: StageVerify, Scope: ActionField, Err: fmt.Errorf("%w: unknown action %q", ErrInvalidData, name)}
+}
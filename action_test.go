@@ -0,0 +1,78 @@
+package gojsonforms_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+	"github.com/TobiEiss/go-jsonforms/models"
+)
+
+func TestActions(t *testing.T) {
+	form, err := gojsonforms.NewBuilder().
+		WithSchemaBytes([]byte(`{
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string"},
+				"age": {"type": "integer"}
+			}
+		}`)).
+		WithPostLink("items").
+		WithActions(
+			models.Action{Name: "draft", Label: "Save draft", Style: "link", Draft: true},
+			models.Action{Name: "submit", Label: "Submit", Style: "primary"},
+			models.Action{Name: "delete", Label: "Delete", Style: "error", Target: "items/delete",
+				Confirmation: &models.Confirmation{Title: "Delete {/name}?", Confirm: "Delete", Cancel: "Keep"}},
+		).
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var action models.Action
+	handler := gojsonforms.NewHandler(form).OnSubmit(func(ctx context.Context, data map[string]interface{}) error {
+		action, _ = gojsonforms.ActionFromContext(ctx)
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	for _, expected := range []string{
+		`<button class="btn btn-link" type="submit" name="_action" value="draft" formnovalidate>Save draft</button>`,
+		`<button class="btn btn-primary" type="submit" name="_action" value="submit">Submit</button>`,
+		`<button class="btn btn-error" type="submit" name="_action" value="delete" formaction="/items/delete" hx-post="/items/delete" data-jsonforms-confirm="jsonforms-confirm-delete">Delete</button>`,
+		`<dialog id="jsonforms-confirm-delete"`,
+	} {
+		if !strings.Contains(w.Body.String(), expected) {
+			t.Errorf("expected %s in:\n%s", expected, w.Body.String())
+		}
+	}
+
+	tests := []struct {
+		name     string
+		values   url.Values
+		code     int
+		expected string
+	}{
+		{"draft skips required", url.Values{"_action": {"draft"}, "/age": {"3"}}, http.StatusNoContent, "draft"},
+		{"draft checks types", url.Values{"_action": {"draft"}, "/age": {"x"}}, http.StatusOK, ""},
+		{"submit checks required", url.Values{"_action": {"submit"}, "/age": {"3"}}, http.StatusOK, ""},
+		{"submit", url.Values{"_action": {"submit"}, "/name": {"John"}}, http.StatusNoContent, "submit"},
+		{"first action without name", url.Values{"/age": {"3"}}, http.StatusNoContent, "draft"},
+		{"unknown action", url.Values{"_action": {"drop"}, "/name": {"John"}}, http.StatusBadRequest, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			action = models.Action{}
+			w := post(handler, test.values, true)
+			if w.Code != test.code || action.Name != test.expected {
+				t.Errorf("expected %d with action %q, got %d with %q:\n%s", test.code, test.expected, w.Code, action.Name, w.Body.String())
+			}
+		})
+	}
+}
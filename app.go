@@ -0,0 +1,299 @@
+package gojsonforms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+	"github.com/TobiEiss/go-jsonforms/models"
+)
+
+// ManifestFile orders the screens of an App and gives them titles:
+//
+//	{"screens": [{"link": "basic", "title": "Basic"}, {"link": "array"}]}
+//
+// Screens not listed follow in alphabetical order.
+const ManifestFile = "manifest.json"
+
+// appAssets is the path the app serves the embedded assets at.
+const appAssets = "_jsonforms"
+
+// ScreenSubmitFunc is called with the verified data submitted on screen, see
+// SubmitFunc.
+type ScreenSubmitFunc func(ctx context.Context, screen string, data map[string]interface{}) error
+
+type manifest struct {
+	Screens []struct {
+		Link  string `json:"link"`
+		Title string `json:"title"`
+	} `json:"screens"`
+}
+
+type appBuilder struct {
+	fsys         fs.FS
+	onSubmit     ScreenSubmitFunc
+	cssPath      string
+	logoPath     string
+	confirmation models.Confirmation
+	basePath     string
+	csrf         CSRF
+	log          *slog.Logger
+}
+
+// App serves a form per screen folder of a file system, with a menu of all
+// screens. Every folder holding a schema.json is a screen, uischema.json and
+// data.json are optional:
+//
+//	screens/
+//		manifest.json
+//		basic/schema.json
+//		basic/uischema.json
+//		array/schema.json
+//
+// A screen is served and submitted at /<folder> below the base path, /
+// redirects to the first screen. The pages load htmx and the default
+// stylesheet from the app itself, at /_jsonforms. An App is an http.Handler
+// and safe for concurrent use.
+type App struct {
+	screens  []*screen
+	byLink   map[string]*screen
+	basePath string
+	assets   http.Handler
+}
+
+type screen struct {
+	link    string
+	title   string
+	handler *Handler
+}
+
+// NewApp creates an app of the screen folders in fsys.
+func NewApp(fsys fs.FS) *appBuilder {
+	return &appBuilder{fsys: fsys}
+}
+
+// OnSubmit sets the function called with the data of every valid submit.
+func (b *appBuilder) OnSubmit(fn ScreenSubmitFunc) *appBuilder {
+	b.onSubmit = fn
+	return b
+}
+
+func (b *appBuilder) WithCss(cssPath string) *appBuilder {
+	b.cssPath = cssPath
+	return b
+}
+
+func (b *appBuilder) WithLogo(logoPath string) *appBuilder {
+	b.logoPath = logoPath
+	return b
+}
+
+func (b *appBuilder) WithConfirmation(c models.Confirmation) *appBuilder {
+	b.confirmation = c
+	return b
+}
+
+// WithBasePath prefixes every URL of the app, see the WithBasePath of the
+// form builder.
+func (b *appBuilder) WithBasePath(basePath string) *appBuilder {
+	b.basePath = basePath
+	return b
+}
+
+// WithCSRF protects all screens against cross-site request forgery, see the
+// WithCSRF of Handler.
+func (b *appBuilder) WithCSRF(c CSRF) *appBuilder {
+	b.csrf = c
+	return b
+}
+
+// WithLogger sets the logger for debug events of all screens.
+func (b *appBuilder) WithLogger(logger *slog.Logger) *appBuilder {
+	b.log = logger
+	return b
+}
+
+// Compile reads and compiles every screen.
+func (b *appBuilder) Compile() (*App, error) {
+	menu, err := b.menu()
+	if err != nil {
+		return nil, err
+	}
+	if len(menu) == 0 {
+		return nil, &FormError{Stage: StageRead, Err: fmt.Errorf("%w: no screen folder found", ErrNoSchema)}
+	}
+
+	app := &App{
+		byLink:   map[string]*screen{},
+		basePath: form.CleanBasePath(b.basePath),
+		assets:   http.StripPrefix("/"+appAssets, AssetHandler()),
+	}
+	var errs []error
+	for _, item := range menu {
+		s, err := b.screen(item.Link, menu)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("screen %s: %w", item.Link, err))
+			continue
+		}
+		app.screens = append(app.screens, s)
+		app.byLink[s.link] = s
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return app, nil
+}
+
+// menu lists the screen folders in the order of the manifest.
+func (b *appBuilder) menu() ([]models.MenuItem, error) {
+	entries, err := fs.ReadDir(b.fsys, ".")
+	if err != nil {
+		return nil, &FormError{Stage: StageRead, Err: err}
+	}
+	var folders []string
+	for _, entry := range entries {
+		if entry.IsDir() && exists(b.fsys, path.Join(entry.Name(), "schema.json")) {
+			folders = append(folders, entry.Name())
+		}
+	}
+	sort.Strings(folders)
+
+	var m manifest
+	if raw, err := fs.ReadFile(b.fsys, ManifestFile); err == nil {
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return nil, &FormError{Stage: StageRead, Err: fmt.Errorf("%s: %w", ManifestFile, err)}
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, &FormError{Stage: StageRead, Err: err}
+	}
+
+	menu := []models.MenuItem{}
+	listed := map[string]bool{}
+	for _, s := range m.Screens {
+		if !exists(b.fsys, path.Join(s.Link, "schema.json")) {
+			return nil, &FormError{Stage: StageRead, Err: fmt.Errorf("%w: %s lists screen %q without schema.json", ErrNoSchema, ManifestFile, s.Link)}
+		}
+		menu = append(menu, models.MenuItem{Link: s.Link, Titel: s.Title})
+		listed[s.Link] = true
+	}
+	for _, folder := range folders {
+		if !listed[folder] {
+			menu = append(menu, models.MenuItem{Link: folder})
+		}
+	}
+	return menu, nil
+}
+
+// screen compiles the form of a screen folder with its own copy of the menu.
+func (b *appBuilder) screen(link string, menu []models.MenuItem) (*screen, error) {
+	builder := NewBuilder().
+		WithPostLink(link).
+		WithCss(b.cssPath).
+		WithLogo(b.logoPath).
+		WithConfirmation(b.confirmation).
+		WithBasePath(b.basePath).
+		WithAssets(appAssets).
+		WithLogger(b.log)
+
+	schema, err := fs.ReadFile(b.fsys, path.Join(link, "schema.json"))
+	if err != nil {
+		return nil, &FormError{Stage: StageRead, Err: err}
+	}
+	builder.WithSchemaBytes(schema)
+
+	if uiSchema, err := fs.ReadFile(b.fsys, path.Join(link, "uischema.json")); err == nil {
+		builder.WithUISchemaBytes(uiSchema)
+	}
+	if data, err := fs.ReadFile(b.fsys, path.Join(link, "data.json")); err == nil {
+		builder.WithDataBytes(data)
+	}
+
+	// titles fall back to the schema title and the folder name
+	current := make([]models.MenuItem, len(menu))
+	for i, item := range menu {
+		current[i] = item
+		current[i].Current = item.Link == link
+		if current[i].Titel == "" {
+			current[i].Titel = schemaTitle(b.fsys, item.Link)
+		}
+	}
+	builder.WithMenu(current)
+
+	f, err := builder.Compile()
+	if err != nil {
+		return nil, err
+	}
+
+	handler := NewHandler(f).WithCSRF(b.csrf)
+	if b.onSubmit != nil {
+		handler.OnSubmit(func(ctx context.Context, data map[string]interface{}) error {
+			return b.onSubmit(ctx, link, data)
+		})
+	}
+	s := &screen{link: link, handler: handler}
+	for _, item := range current {
+		if item.Current {
+			s.title = item.Titel
+		}
+	}
+	return s, nil
+}
+
+func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	link := strings.Trim(r.URL.Path, "/")
+	if link == "" {
+		base, ok := BasePathFromContext(r.Context())
+		if !ok {
+			base = a.basePath
+		}
+		http.Redirect(w, r, form.Link(base, a.screens[0].link), http.StatusFound)
+		return
+	}
+	if link == appAssets || strings.HasPrefix(link, appAssets+"/") {
+		a.assets.ServeHTTP(w, r)
+		return
+	}
+
+	s, ok := a.byLink[link]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	s.handler.ServeHTTP(w, r)
+}
+
+// Menu returns the menu of all screens, in order.
+func (a *App) Menu() []models.MenuItem {
+	menu := make([]models.MenuItem, len(a.screens))
+	for i, s := range a.screens {
+		menu[i] = models.MenuItem{Link: s.link, Titel: s.title}
+	}
+	return menu
+}
+
+func exists(fsys fs.FS, name string) bool {
+	_, err := fs.Stat(fsys, name)
+	return err == nil
+}
+
+// schemaTitle reads the title of a screen's schema, else the folder name.
+func schemaTitle(fsys fs.FS, link string) string {
+	var schema struct {
+		Title string `json:"title"`
+	}
+	if raw, err := fs.ReadFile(fsys, path.Join(link, "schema.json")); err == nil {
+		json.Unmarshal(raw, &schema)
+	}
+	if schema.Title != "" {
+		return schema.Title
+	}
+	return link
+}
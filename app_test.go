@@ -0,0 +1,124 @@
+package gojsonforms_test
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+)
+
+func TestApp(t *testing.T) {
+	fsys := fstest.MapFS{
+		"manifest.json":      {Data: []byte(`{"screens": [{"link": "second", "title": "Second screen"}]}`)},
+		"first/schema.json":  {Data: []byte(`{"title": "First screen", "properties": {"name": {"type": "string"}}}`)},
+		"second/schema.json": {Data: []byte(`{"properties": {"age": {"type": "integer"}}}`)},
+		"second/data.json":   {Data: []byte(`{"age": 42}`)},
+		"assets/style.css":   {Data: []byte(`body {}`)},
+	}
+
+	var submitted string
+	app, err := gojsonforms.NewApp(fsys).
+		OnSubmit(func(ctx context.Context, screen string, data map[string]interface{}) error {
+			submitted = screen
+			return nil
+		}).
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	menu := app.Menu()
+	if len(menu) != 2 || menu[0].Link != "second" || menu[0].Titel != "Second screen" || menu[1].Titel != "First screen" {
+		t.Errorf("unexpected menu %+v", menu)
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/second" {
+		t.Errorf("expected redirect to the first screen, got %d %v", w.Code, w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/second", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, `<h1>Second screen</h1>`) || !strings.Contains(body, `value="42"`) || !strings.Contains(body, `hx-post="/second"`) {
+		t.Errorf("unexpected screen:\n%s", body)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/first", strings.NewReader(url.Values{"/name": {"John"}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("HX-Request", "true")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent || submitted != "first" {
+		t.Errorf("expected submit of first, got %d and %q", w.Code, submitted)
+	}
+
+	if !strings.Contains(body, `<link rel="stylesheet" href="/_jsonforms/jsonforms.css?v=`) {
+		t.Errorf("expected the embedded stylesheet in:\n%s", body)
+	}
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/_jsonforms/jsonforms.css", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected the app to serve its assets, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/assets", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for folders without schema, got %d", w.Code)
+	}
+}
+
+func TestAppArraySelect(t *testing.T) {
+	var submitted map[string]interface{}
+	app, err := gojsonforms.NewApp(os.DirFS("testdata")).
+		OnSubmit(func(ctx context.Context, screen string, data map[string]interface{}) error {
+			submitted = data
+			return nil
+		}).
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every field of the page, named after the selected element
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/arraySelect", nil))
+	values := url.Values{}
+	for _, field := range regexp.MustCompile(`name="([^"]+)"[^>]*value="([^"]*)"`).FindAllStringSubmatch(w.Body.String(), -1) {
+		values.Set(html.UnescapeString(field[1]), html.UnescapeString(field[2]))
+	}
+	if values.Get("/comments/0/person/firstname") != "John" {
+		t.Fatalf("expected the detail of the first element, got %v in:\n%s", values, w.Body.String())
+	}
+	values.Set("/comments/0/message", "Edited")
+
+	r := httptest.NewRequest(http.MethodPost, "/arraySelect", strings.NewReader(values.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d:\n%s", w.Code, w.Body.String())
+	}
+	expected := map[string]interface{}{
+		"comments": []interface{}{
+			map[string]interface{}{
+				"person":  map[string]interface{}{"firstname": "John", "lastname": "Doe"},
+				"message": "Edited",
+			},
+		},
+	}
+	if !reflect.DeepEqual(submitted, expected) {
+		t.Errorf("expected %v, got %v", expected, submitted)
+	}
+}
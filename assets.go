@@ -0,0 +1,22 @@
+package gojsonforms
+
+import (
+	"net/http"
+
+	"github.com/TobiEiss/go-jsonforms/internal/assets"
+)
+
+// HtmxVersion is the pinned htmx release the pages load.
+const HtmxVersion = assets.HtmxVersion
+
+// AssetHandler serves the embedded htmx, script and stylesheet of the pages
+// built WithAssets, with subresource integrity hashes in the page and cache
+// headers for versioned URLs. Mount it at the assets path:
+//
+//	mux.Handle("/assets/", http.StripPrefix("/assets", gojsonforms.AssetHandler()))
+//
+// htmx is vendored with `make assets`. The stylesheet is no copy of Spectre
+// but a stylesheet of its own, covering the classes of the embedded templates.
+func AssetHandler() http.Handler {
+	return assets.Handler()
+}
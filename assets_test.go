@@ -0,0 +1,74 @@
+package gojsonforms_test
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/base64"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+)
+
+func TestAssets(t *testing.T) {
+	f, err := gojsonforms.NewBuilder().
+		WithSchemaBytes([]byte(`{"properties": {"name": {"type": "string"}}}`)).
+		WithBasePath("/admin").
+		WithAssets("assets").
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	if err := f.Render(context.Background(), &sb, gojsonforms.RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	page := sb.String()
+
+	css := regexp.MustCompile(`<link rel="stylesheet" href="/admin/assets/(jsonforms\.css\?v=\w+)" integrity="([^"]+)">`).FindStringSubmatch(page)
+	if css == nil {
+		t.Fatalf("expected embedded stylesheet in:\n%s", page)
+	}
+
+	handler := gojsonforms.AssetHandler()
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/"+css[1], nil))
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/css") {
+		t.Fatalf("unexpected response %d %v", w.Code, w.Header())
+	}
+	if cache := w.Header().Get("Cache-Control"); !strings.Contains(cache, "immutable") {
+		t.Errorf("expected versioned asset to be cached forever, got %q", cache)
+	}
+	sum := sha512.Sum384(w.Body.Bytes())
+	if integrity := "sha384-" + base64.StdEncoding.EncodeToString(sum[:]); integrity != html.UnescapeString(css[2]) {
+		t.Errorf("integrity %s doesn't match the served stylesheet %s", css[2], integrity)
+	}
+
+	// unversioned requests are revalidated
+	etag := w.Header().Get("ETag")
+	r := httptest.NewRequest(http.MethodGet, "/jsonforms.css", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified || w.Header().Get("Cache-Control") != "no-cache" {
+		t.Errorf("expected 304 with no-cache, got %d %v", w.Code, w.Header())
+	}
+
+	// htmx is embedded, nothing is loaded from a CDN
+	if !strings.Contains(page, `<script src="/admin/assets/htmx.min.js?v=`) {
+		t.Errorf("expected embedded htmx in:\n%s", page)
+	}
+	if strings.Contains(page, "https://") {
+		t.Errorf("expected no external URL in:\n%s", page)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/jsonforms.css", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+}
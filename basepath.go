@@ -0,0 +1,52 @@
+package gojsonforms
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+)
+
+type basePathKey struct{}
+
+// ContextWithBasePath returns a context whose renders prefix every URL of the
+// page with base, see WithBasePath.
+func ContextWithBasePath(ctx context.Context, base string) context.Context {
+	return context.WithValue(ctx, basePathKey{}, form.CleanBasePath(base))
+}
+
+// BasePathFromContext returns the base path set by ContextWithBasePath or
+// Mount.
+func BasePathFromContext(ctx context.Context) (string, bool) {
+	base, ok := ctx.Value(basePathKey{}).(string)
+	return base, ok
+}
+
+// Mount serves h below prefix: the prefix is stripped from the request path,
+// if present, and becomes the base path of every form rendered for the
+// request.
+//
+//	mux.Handle("/admin/settings/", gojsonforms.Mount("/admin/settings", app))
+//
+// Behind a reverse proxy that strips its path prefix, mount at the public
+// prefix all the same.
+func Mount(prefix string, h http.Handler) http.Handler {
+	prefix = form.CleanBasePath(prefix)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r2 := r.WithContext(ContextWithBasePath(r.Context(), prefix))
+		if p := strings.TrimPrefix(r.URL.Path, prefix); prefix != "" && len(p) < len(r.URL.Path) && (p == "" || p[0] == '/') {
+			u := *r.URL
+			u.Path = p
+			u.RawPath = ""
+			r2.URL = &u
+		}
+		h.ServeHTTP(w, r2)
+	})
+}
+
+// Link joins the base path of a request and a path of the app to an URL.
+func Link(ctx context.Context, path string) string {
+	base, _ := BasePathFromContext(ctx)
+	return form.Link(base, path)
+}
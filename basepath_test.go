@@ -0,0 +1,109 @@
+package gojsonforms_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+	"github.com/TobiEiss/go-jsonforms/models"
+)
+
+func TestLink(t *testing.T) {
+	tests := []struct {
+		base, path, expected string
+	}{
+		{"", "basic", "/basic"},
+		{"", "/basic", "/basic"},
+		{"/admin", "basic", "/admin/basic"},
+		{"/admin/", "/basic", "/admin/basic"},
+		{"admin/settings", "basic", "/admin/settings/basic"},
+		{"/admin", "https://example.com/style.css", "https://example.com/style.css"},
+		{"/admin", "//cdn.example.com/style.css", "//cdn.example.com/style.css"},
+	}
+	for _, test := range tests {
+		ctx := gojsonforms.ContextWithBasePath(context.Background(), test.base)
+		if link := gojsonforms.Link(ctx, test.path); link != test.expected {
+			t.Errorf("Link(%q, %q) = %q, expected %q", test.base, test.path, link, test.expected)
+		}
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	f, err := gojsonforms.NewBuilder().
+		WithSchemaBytes([]byte(`{"properties": {"name": {"type": "string"}}}`)).
+		WithPostLink("settings").
+		WithCss("/style.css").
+		WithMenu([]models.MenuItem{{Link: "settings", Titel: "Settings"}}).
+		WithBasePath("/admin").
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := f.Render(context.Background(), &sb, gojsonforms.RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`href="/admin/style.css"`, `href="/admin/settings"`, `hx-post="/admin/settings"`} {
+		if !strings.Contains(sb.String(), expected) {
+			t.Errorf("expected %s in:\n%s", expected, sb.String())
+		}
+	}
+
+	// per request, the base path of the render options wins
+	sb.Reset()
+	ctx := gojsonforms.ContextWithBasePath(context.Background(), "/ctx")
+	if err := f.Render(ctx, &sb, gojsonforms.RenderOptions{BasePath: "/tenant"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), `hx-post="/tenant/settings"`) {
+		t.Errorf("expected base path of the options in:\n%s", sb.String())
+	}
+}
+
+func TestMount(t *testing.T) {
+	fsys := fstest.MapFS{
+		"first/schema.json":  {Data: []byte(`{"properties": {"name": {"type": "string"}}}`)},
+		"second/schema.json": {Data: []byte(`{"properties": {"age": {"type": "integer"}}}`)},
+	}
+	app, err := gojsonforms.NewApp(fsys).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/settings/", gojsonforms.Mount("/admin/settings/", app))
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/settings/", nil))
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/admin/settings/first" {
+		t.Errorf("expected redirect to the first screen, got %d %v", w.Code, w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/settings/second", nil))
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.Contains(body, `href="/admin/settings/first"`) || !strings.Contains(body, `hx-post="/admin/settings/second"`) {
+		t.Errorf("expected links below the mount prefix, got %d:\n%s", w.Code, body)
+	}
+
+	// submits without htmx are redirected back to the form
+	r := httptest.NewRequest(http.MethodPost, "/admin/settings/first", strings.NewReader("/name=John"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/admin/settings/first" {
+		t.Errorf("expected redirect to the form, got %d %v", w.Code, w.Header())
+	}
+
+	// behind a proxy that strips the prefix
+	w = httptest.NewRecorder()
+	gojsonforms.Mount("/admin/settings", app).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/second", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `hx-post="/admin/settings/second"`) {
+		t.Errorf("expected links below the public prefix, got %d", w.Code)
+	}
+}
@@ -0,0 +1,59 @@
+// Command jsonforms-gen generates Go types for a JSON schema, to decode the
+// data verified by a form into, see gojsonforms.Decode:
+//
+//	jsonforms-gen [-type Settings] [-package settings] [-o settings_gen.go] schema.json
+//
+// Run it with go generate next to the schema:
+//
+//	//go:generate go run github.com/TobiEiss/go-jsonforms/cmd/jsonforms-gen -type Settings -o settings_gen.go schema.json
+//
+// Objects become structs, nested objects types named after their parent and
+// field, string enums typed string constants and $defs named types. Fields
+// not required or nullable are pointers.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/TobiEiss/go-jsonforms/internal/schema"
+)
+
+func main() {
+	typeName := flag.String("type", "", "name of the schema's type, else its title")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated file, main by default")
+	output := flag.String("o", "", "file to write, else standard output")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: jsonforms-gen [flags] schema.json")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Arg(0), *output, schema.GoOptions{Package: *pkg, Type: *typeName}); err != nil {
+		fmt.Fprintln(os.Stderr, "jsonforms-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(input, output string, opts schema.GoOptions) error {
+	raw, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+	opts.Source = filepath.Base(input)
+	src, err := schema.GenerateGo(raw, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", input, err)
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(output, src, 0o644)
+}
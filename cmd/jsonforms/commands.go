@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	gabs "github.com/Jeffail/gabs/v2"
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+)
+
+// inputs are the read files of a command.
+type inputs struct {
+	schema   []byte
+	uiSchema []byte
+	data     []byte
+}
+
+// load reads the given files, false if one can't be read.
+func (f *files) load(stderr io.Writer) (inputs, bool) {
+	var in inputs
+	for _, file := range []struct {
+		name string
+		dst  *[]byte
+	}{{f.schema, &in.schema}, {f.uiSchema, &in.uiSchema}, {f.data, &in.data}} {
+		b, err := read(file.name)
+		if err != nil {
+			fmt.Fprintln(stderr, "jsonforms:", err)
+			return in, false
+		}
+		*file.dst = b
+	}
+	return in, true
+}
+
+// compile compiles the form of the inputs.
+func (in inputs) compile() (*gojsonforms.Form, error) {
+	builder := gojsonforms.NewBuilder().WithSchemaBytes(in.schema)
+	if in.uiSchema != nil {
+		builder.WithUISchemaBytes(in.uiSchema)
+	}
+	if in.data != nil {
+		builder.WithDataBytes(in.data)
+	}
+	return builder.Compile()
+}
+
+// render writes the form as full page or fragment.
+func render(args []string, stdout, stderr io.Writer) int {
+	var f files
+	fs := f.flagSet("render", stderr)
+	fragment := fs.Bool("fragment", false, "write the form without the surrounding page")
+	output := fs.String("o", "", "file to write, else standard output")
+	if err := f.parse(fs, args); err != nil {
+		return parseExit(err)
+	}
+	in, ok := f.load(stderr)
+	if !ok {
+		return exitUsage
+	}
+
+	compiled, err := in.compile()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitProblems
+	}
+	var page bytes.Buffer
+	if *fragment {
+		err = compiled.RenderFragment(context.Background(), &page, gojsonforms.RenderOptions{})
+	} else {
+		err = compiled.Render(context.Background(), &page, gojsonforms.RenderOptions{})
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitProblems
+	}
+
+	if *output == "" {
+		_, err = stdout.Write(page.Bytes())
+	} else {
+		err = os.WriteFile(*output, page.Bytes(), 0o644)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "jsonforms:", err)
+		return exitUsage
+	}
+	return exitOK
+}
+
+// lint reports the problems of schema and UI schema: scopes that don't
+// resolve, unknown element types, malformed elements and unresolved refs.
+func lint(args []string, stdout, stderr io.Writer) int {
+	var f files
+	fs := f.flagSet("lint", stderr)
+	if err := f.parse(fs, args); err != nil {
+		return parseExit(err)
+	}
+	in, ok := f.load(stderr)
+	if !ok {
+		return exitUsage
+	}
+
+	problems := 0
+	report := func(file string, p form.Problem) {
+		fmt.Fprintf(stdout, "%s: %s\n", file, p)
+		problems++
+	}
+
+	schema, err := gabs.ParseJSON(in.schema)
+	if err != nil {
+		fmt.Fprintf(stdout, "%s: %v\n", f.schema, err)
+		return exitProblems
+	}
+	for _, p := range form.UnresolvedRefs(schema) {
+		report(f.schema, p)
+	}
+
+	if in.uiSchema != nil {
+		uiSchema, err := gabs.ParseJSON(in.uiSchema)
+		if err != nil {
+			fmt.Fprintf(stdout, "%s: %v\n", f.uiSchema, err)
+			return exitProblems
+		}
+		var uiErr *form.UISchemaError
+		if err := form.ValidateUISchema(schema, uiSchema); errors.As(err, &uiErr) {
+			for _, p := range uiErr.Problems {
+				report(f.uiSchema, p)
+			}
+		}
+	}
+	if problems > 0 {
+		return exitProblems
+	}
+
+	// whatever else keeps the form from compiling
+	if _, err := in.compile(); err != nil {
+		fmt.Fprintln(stdout, err)
+		return exitProblems
+	}
+	return exitOK
+}
+
+// genUISchema prints the default UI schema of the schema.
+func genUISchema(args []string, stdout, stderr io.Writer) int {
+	var f files
+	fs := f.flagSet("gen-uischema", stderr)
+	if err := f.parse(fs, args); err != nil {
+		return parseExit(err)
+	}
+	in, ok := f.load(stderr)
+	if !ok {
+		return exitUsage
+	}
+
+	// the default one, whatever the folder holds
+	in.uiSchema = nil
+	compiled, err := in.compile()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitProblems
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, compiled.UISchema(), "", "  "); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitProblems
+	}
+	out.WriteString("\n")
+	stdout.Write(out.Bytes())
+	return exitOK
+}
+
+// validate checks the data against the schema and prints a line per invalid
+// value.
+func validate(args []string, stdout, stderr io.Writer) int {
+	var f files
+	fs := f.flagSet("validate", stderr)
+	if err := f.parse(fs, args); err != nil {
+		return parseExit(err)
+	}
+	if f.data == "" {
+		fmt.Fprintln(stderr, "jsonforms validate: no data, use -data or a folder with data.json")
+		return exitUsage
+	}
+	in, ok := f.load(stderr)
+	if !ok {
+		return exitUsage
+	}
+
+	// the data is checked by the schema only
+	data := in.data
+	in.uiSchema, in.data = nil, nil
+	compiled, err := in.compile()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitProblems
+	}
+
+	err = compiled.Validate(data)
+	if err == nil {
+		return exitOK
+	}
+	if fields := gojsonforms.FieldErrors(err); errors.Is(err, gojsonforms.ErrValidation) && len(fields) > 0 {
+		fieldErrors(stdout, f.data, fields)
+	} else {
+		fmt.Fprintf(stdout, "%s: %v\n", f.data, err)
+	}
+	return exitProblems
+}
@@ -0,0 +1,171 @@
+// Command jsonforms renders and checks forms without writing a Go program:
+//
+//	jsonforms render [-fragment] [-o form.html] <files>
+//	jsonforms lint <files>
+//	jsonforms gen-uischema <files>
+//	jsonforms validate <files>
+//	jsonforms serve [-addr localhost:8080] folder
+//
+// The files are given by -schema, -uischema and -data, or as a screen folder
+// holding schema.json and the optional uischema.json and data.json, like the
+// folders of an App:
+//
+//	jsonforms lint testdata/basic
+//	jsonforms validate -schema schema.json -data submitted.json
+//
+// serve is a development server for the screen folders of a folder. It
+// rebuilds the screens when a file changes, reloads the pages in the browser
+// and shows errors of the build over the page.
+//
+// The exit code is 0 on success, 1 if the form or data has problems and 2 for
+// wrong usage or unreadable files, for use in CI.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Exit codes of the commands.
+const (
+	exitOK       = 0
+	exitProblems = 1
+	exitUsage    = 2
+)
+
+// commands by name, see usage
+var commands = map[string]func(args []string, stdout, stderr io.Writer) int{
+	"render":       render,
+	"lint":         lint,
+	"gen-uischema": genUISchema,
+	"validate":     validate,
+	"serve":        serve,
+}
+
+const usage = `usage: jsonforms <command> [flags] [folder]
+
+commands:
+  render        write the form as HTML page or fragment
+  lint          check UI schema scopes, element types and schema refs
+  gen-uischema  print the default UI schema of the schema
+  validate      check data against the schema
+  serve         serve the screen folders of a folder with live reload
+
+Run jsonforms <command> -h for the flags of a command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			fmt.Fprint(stdout, usage)
+			return exitOK
+		}
+		fmt.Fprintf(stderr, "jsonforms: unknown command %q\n\n%s", args[0], usage)
+		return exitUsage
+	}
+	return command(args[1:], stdout, stderr)
+}
+
+// files are the inputs of a command.
+type files struct {
+	schema   string
+	uiSchema string
+	data     string
+}
+
+// flagSet creates the flags of a command with the file flags.
+func (f *files) flagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&f.schema, "schema", "", "JSON schema file")
+	fs.StringVar(&f.uiSchema, "uischema", "", "UI schema file, the default one if empty")
+	fs.StringVar(&f.data, "data", "", "data file")
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: jsonforms %s [flags] [folder]\n\nThe folder gives schema.json, uischema.json and data.json for missing flags.\n\n", name)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// errUsage is returned by parse for arguments it reported already.
+var errUsage = errors.New("usage")
+
+// parse parses the arguments of a command and fills the files missing in the
+// flags from the folder argument.
+func (f *files) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	fail := func(format string, args ...interface{}) error {
+		fmt.Fprintf(fs.Output(), "jsonforms %s: %s\n", fs.Name(), fmt.Sprintf(format, args...))
+		return errUsage
+	}
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		folder := fs.Arg(0)
+		if info, err := os.Stat(folder); err != nil || !info.IsDir() {
+			return fail("%s is no folder", folder)
+		}
+		for file, name := range map[*string]string{&f.schema: "schema.json", &f.uiSchema: "uischema.json", &f.data: "data.json"} {
+			if path := filepath.Join(folder, name); *file == "" && exists(path) {
+				*file = path
+			}
+		}
+	default:
+		return fail("too many arguments")
+	}
+	if f.schema == "" {
+		return fail("no schema, use -schema or a folder with schema.json")
+	}
+	return nil
+}
+
+// read reads a file of the inputs, nil if it isn't given.
+func read(name string) ([]byte, error) {
+	if name == "" {
+		return nil, nil
+	}
+	return os.ReadFile(name)
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// parseExit is the exit code of an error of parse, which reported it already.
+func parseExit(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	return exitUsage
+}
+
+// fieldErrors prints the messages of field errors, sorted by data pointer.
+func fieldErrors(w io.Writer, file string, fields map[string][]string) {
+	pointers := make([]string, 0, len(fields))
+	for pointer := range fields {
+		pointers = append(pointers, pointer)
+	}
+	sort.Strings(pointers)
+	for _, pointer := range pointers {
+		for _, message := range fields[pointer] {
+			fmt.Fprintf(w, "%s: %s: %s\n", file, pointer, message)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	schema := write("schema.json", `{
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "minLength": 3},
+			"age": {"type": "integer"},
+			"address": {"$ref": "#/$defs/address"},
+			"other": {"$ref": "#/$defs/missing"}
+		},
+		"$defs": {"address": {"type": "object"}}
+	}`)
+	uiSchema := write("uischema.json", `{"type": "VerticalLayout", "elements": [
+		{"type": "Control", "scope": "#/properties/name"},
+		{"type": "Control", "scope": "#/properties/unknown"},
+		{"type": "Slider"}
+	]}`)
+	valid := write("valid.json", `{"name": "John", "age": 42}`)
+	invalid := write("invalid.json", `{"age": 4.2}`)
+	broken := write("broken.json", `{"name": `)
+
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout []string
+		stderr string
+	}{
+		{name: "no command", code: exitUsage, stderr: "usage: jsonforms"},
+		{name: "help", args: []string{"help"}, code: exitOK, stdout: []string{"gen-uischema"}},
+		{name: "unknown command", args: []string{"deploy"}, code: exitUsage, stderr: `unknown command "deploy"`},
+		{name: "command help", args: []string{"lint", "-h"}, code: exitOK, stderr: "-schema"},
+		{name: "unknown flag", args: []string{"lint", "-x"}, code: exitUsage, stderr: "-x"},
+		{name: "no schema", args: []string{"lint"}, code: exitUsage, stderr: "no schema"},
+		{name: "missing file", args: []string{"lint", "-schema", filepath.Join(dir, "missing.json")}, code: exitUsage, stderr: "missing.json"},
+		{name: "lint folder", args: []string{"lint", "../../testdata/basic"}, code: exitOK},
+		{
+			name: "lint problems",
+			args: []string{"lint", "-schema", schema, "-uischema", uiSchema},
+			code: exitProblems,
+			stdout: []string{
+				`schema.json: /properties/other/$ref: unresolved $ref: "#/$defs/missing"`,
+				`uischema.json: /elements/1/scope: unresolved scope: "#/properties/unknown"`,
+				`uischema.json: /elements/2/type: unknown element type: "Slider"`,
+			},
+		},
+		{name: "lint invalid json", args: []string{"lint", "-schema", broken}, code: exitProblems, stdout: []string{"broken.json"}},
+		{name: "render", args: []string{"render", "../../testdata/basic"}, code: exitOK, stdout: []string{"<html", `name="/name"`}},
+		{name: "render fragment", args: []string{"render", "-fragment", "-data", valid, "-schema", schema}, code: exitOK, stdout: []string{`value="John"`}},
+		{name: "render invalid", args: []string{"render", "-schema", schema, "-uischema", uiSchema}, code: exitProblems, stderr: "unresolved scope"},
+		{name: "gen-uischema", args: []string{"gen-uischema", "-schema", schema}, code: exitOK, stdout: []string{`"scope": "#/properties/age"`}},
+		{name: "validate", args: []string{"validate", "-schema", schema, "-data", valid}, code: exitOK},
+		{
+			name:   "validate problems",
+			args:   []string{"validate", "-schema", schema, "-data", invalid},
+			code:   exitProblems,
+			stdout: []string{"invalid.json: /age: must be an integer", "invalid.json: /name: is required"},
+		},
+		{name: "validate invalid json", args: []string{"validate", "-schema", schema, "-data", broken}, code: exitProblems, stdout: []string{"invalid data"}},
+		{name: "validate without data", args: []string{"validate", "-schema", schema}, code: exitUsage, stderr: "no data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, &stdout, &stderr)
+			if code != tt.code {
+				t.Errorf("expected exit code %d, got %d\nstdout: %s\nstderr: %s", tt.code, code, stdout.String(), stderr.String())
+			}
+			for _, s := range tt.stdout {
+				if !strings.Contains(stdout.String(), s) {
+					t.Errorf("expected %q in stdout:\n%s", s, stdout.String())
+				}
+			}
+			if !strings.Contains(stderr.String(), tt.stderr) {
+				t.Errorf("expected %q in stderr:\n%s", tt.stderr, stderr.String())
+			}
+		})
+	}
+}
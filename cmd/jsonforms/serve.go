@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+)
+
+// reloadPath is where the pages of serve listen for changes.
+const reloadPath = "/_jsonforms/reload"
+
+// reloadScript reloads the page once the screens were rebuilt.
+const reloadScript = `<script>new EventSource("` + reloadPath + `").addEventListener("reload", function () { location.reload(); });</script>`
+
+// serve serves the screen folders of a folder like an App and rebuilds them
+// when a file changes.
+func serve(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	interval := fs.Duration("interval", 500*time.Millisecond, "how often to look for changed files")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, "usage: jsonforms serve [flags] folder\n\nServes every screen folder of folder and reloads the page when a file changes.\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return parseExit(err)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	if info, err := os.Stat(fs.Arg(0)); err != nil || !info.IsDir() {
+		fmt.Fprintf(stderr, "jsonforms serve: %s is no folder\n", fs.Arg(0))
+		return exitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	d := newDevServer(os.DirFS(fs.Arg(0)), stdout, stderr)
+	go d.watch(ctx, *interval)
+
+	server := &http.Server{Addr: *addr, Handler: d}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+	fmt.Fprintf(stderr, "serving %s at http://%s\n", fs.Arg(0), *addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(stderr, "jsonforms serve:", err)
+		return exitProblems
+	}
+	return exitOK
+}
+
+// devServer serves the App of a folder, rebuilt whenever the files of the
+// folder change. Pages reload on rebuilds, errors of the build are shown as an
+// overlay over the last working app.
+type devServer struct {
+	fsys   fs.FS
+	stdout io.Writer
+	stderr io.Writer
+
+	mu    sync.RWMutex
+	app   *gojsonforms.App
+	err   error
+	files map[string]fileState
+	// rebuilt is closed and replaced on every rebuild
+	rebuilt chan struct{}
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+func newDevServer(fsys fs.FS, stdout, stderr io.Writer) *devServer {
+	d := &devServer{fsys: fsys, stdout: stdout, stderr: stderr, rebuilt: make(chan struct{})}
+	d.check()
+	return d
+}
+
+// watch polls the files every interval until ctx is done.
+func (d *devServer) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.check()
+		}
+	}
+}
+
+// check rebuilds the app if a file was added, changed or removed since the
+// last check, and reports whether it did.
+func (d *devServer) check() bool {
+	files, err := d.scan()
+	if err == nil && d.files != nil && equalFiles(files, d.files) {
+		return false
+	}
+	d.files = files
+
+	var app *gojsonforms.App
+	if err == nil {
+		app, err = gojsonforms.NewApp(d.fsys).
+			OnSubmit(d.submitted).
+			WithLogger(slog.New(failureHandler{slog.NewTextHandler(d.stderr, &slog.HandlerOptions{Level: slog.LevelError})})).
+			Compile()
+	}
+
+	d.mu.Lock()
+	if err == nil {
+		d.app = app
+		fmt.Fprintf(d.stderr, "built %d screens\n", len(app.Menu()))
+	} else {
+		fmt.Fprintf(d.stderr, "build failed: %v\n", err)
+	}
+	d.err = err
+	close(d.rebuilt)
+	d.rebuilt = make(chan struct{})
+	d.mu.Unlock()
+	return true
+}
+
+// scan lists the size and modification time of every file.
+func (d *devServer) scan() (map[string]fileState, error) {
+	files := map[string]fileState{}
+	err := fs.WalkDir(d.fsys, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		files[path] = fileState{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	return files, err
+}
+
+func equalFiles(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		if other, ok := b[path]; !ok || other.size != state.size || !other.modTime.Equal(state.modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// submitted prints the data of valid submits.
+func (d *devServer) submitted(ctx context.Context, screen string, data map[string]interface{}) error {
+	b, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(d.stdout, "%s: %s\n", screen, b)
+	return nil
+}
+
+func (d *devServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == reloadPath {
+		d.events(w, r)
+		return
+	}
+
+	d.mu.RLock()
+	app, err := d.app, d.err
+	d.mu.RUnlock()
+
+	// fragments for htmx are swapped into pages that reload anyway
+	if r.Header.Get("HX-Request") != "" && app != nil {
+		app.ServeHTTP(w, r)
+		return
+	}
+
+	status := http.StatusInternalServerError
+	if app != nil {
+		// errors of the render, like data the screen can't bind, are
+		// shown like build errors
+		f := &failure{}
+		pw := &pageWriter{ResponseWriter: w}
+		app.ServeHTTP(pw, r.WithContext(context.WithValue(r.Context(), failureKey{}, f)))
+		if !pw.failed() {
+			if !pw.page() {
+				return
+			}
+			if err != nil {
+				io.WriteString(w, overlay(err))
+			}
+			io.WriteString(w, reloadScript)
+			return
+		}
+		status = pw.status
+		err = errors.Join(err, f.error(status))
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Del("X-Content-Type-Options")
+	w.WriteHeader(status)
+	io.WriteString(w, "<!DOCTYPE html>\n<title>jsonforms</title>\n")
+	if err != nil {
+		io.WriteString(w, overlay(err))
+	}
+	io.WriteString(w, reloadScript)
+}
+
+type failureKey struct{}
+
+// failure records the error logged while serving a request.
+type failure struct {
+	mu  sync.Mutex
+	err string
+}
+
+// error returns the logged error or else the text of status.
+func (f *failure) error(status int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err == "" {
+		return errors.New(http.StatusText(status))
+	}
+	return errors.New(f.err)
+}
+
+// failureHandler logs to its handler and records the errors of requests
+// with a failure in their context.
+type failureHandler struct {
+	slog.Handler
+}
+
+func (h failureHandler) Handle(ctx context.Context, record slog.Record) error {
+	if f, ok := ctx.Value(failureKey{}).(*failure); ok && record.Level >= slog.LevelError {
+		msg := record.Message
+		record.Attrs(func(attr slog.Attr) bool {
+			if attr.Key == "error" {
+				msg += ": " + attr.Value.String()
+			}
+			return true
+		})
+		f.mu.Lock()
+		f.err = msg
+		f.mu.Unlock()
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h failureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return failureHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h failureHandler) WithGroup(name string) slog.Handler {
+	return failureHandler{h.Handler.WithGroup(name)}
+}
+
+// events sends a reload event on every rebuild, as server-sent events.
+func (d *devServer) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	io.WriteString(w, "retry: 1000\n\n")
+	flusher.Flush()
+
+	for {
+		d.mu.RLock()
+		rebuilt := d.rebuilt
+		d.mu.RUnlock()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-rebuilt:
+			io.WriteString(w, "event: reload\ndata: {}\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// pageWriter passes a response through and remembers whether it is a full
+// HTML page, to append the overlay and reload script to. Server errors are
+// held back, to be answered with the overlay instead.
+type pageWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (p *pageWriter) WriteHeader(status int) {
+	p.status = status
+	if !p.failed() {
+		p.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (p *pageWriter) Write(b []byte) (int, error) {
+	if p.failed() {
+		return len(b), nil
+	}
+	return p.ResponseWriter.Write(b)
+}
+
+func (p *pageWriter) failed() bool {
+	return p.status >= http.StatusInternalServerError
+}
+
+func (p *pageWriter) page() bool {
+	header := p.Header()
+	return strings.HasPrefix(header.Get("Content-Type"), "text/html") &&
+		header.Get("Content-Length") == "" &&
+		(p.status == 0 || p.status == http.StatusOK || p.status == http.StatusUnprocessableEntity)
+}
+
+// overlay shows the errors of a failed build or render over the page.
+func overlay(err error) string {
+	return `<div id="jsonforms-overlay" style="position:fixed;inset:0;z-index:10000;overflow:auto;padding:2rem;background:rgba(20,20,20,.92);color:#fff;font:14px/1.5 monospace">` +
+		`<h2 style="color:#ff6b6b;margin-top:0">jsonforms failed</h2>` +
+		`<pre style="white-space:pre-wrap">` + html.EscapeString(err.Error()) + `</pre>` +
+		`<p style="color:#aaa">The page reloads once the files are fixed.</p></div>`
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDevServer(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// a new time, whatever the resolution of the file system
+		later := time.Now().Add(time.Duration(len(content)) * time.Second)
+		if err := os.Chtimes(path, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("basic/schema.json", `{"type": "object", "properties": {"name": {"type": "string"}}}`)
+
+	var stdout, stderr bytes.Buffer
+	d := newDevServer(os.DirFS(dir), &stdout, &stderr)
+	server := httptest.NewServer(d)
+	defer server.Close()
+	client := server.Client()
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	get := func(path string) (int, string) {
+		res, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+		return res.StatusCode, string(body)
+	}
+
+	status, body := get("/basic")
+	if status != http.StatusOK || !strings.Contains(body, `name="/name"`) || !strings.HasSuffix(body, reloadScript) {
+		t.Fatalf("expected the screen with reload script, got %d:\n%s", status, body)
+	}
+	if status, _ := get("/"); status != http.StatusFound {
+		t.Errorf("expected a redirect to the first screen, got %d", status)
+	}
+	if d.check() {
+		t.Error("rebuilt without changes")
+	}
+
+	// submits are printed
+	res, err := client.PostForm(server.URL+"/basic", url.Values{"/name": {"John"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if !strings.Contains(stdout.String(), `"name": "John"`) {
+		t.Errorf("expected the submitted data, got %q", stdout.String())
+	}
+
+	// pages listen for rebuilds
+	res, err = client.Get(server.URL + reloadPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected an event stream, got %q", ct)
+	}
+	events := bufio.NewReader(res.Body)
+	if line, _ := events.ReadString('\n'); line != "retry: 1000\n" {
+		t.Errorf("unexpected first line %q", line)
+	}
+
+	// broken files keep the last screens with the errors over them
+	write("basic/uischema.json", `{"type": "VerticalLayout", "elements": [{"type": "Control", "scope": "#/properties/unknown"}]}`)
+	if !d.check() {
+		t.Fatal("not rebuilt after a change")
+	}
+	for {
+		line, err := events.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line == "event: reload\n" {
+			break
+		}
+	}
+	status, body = get("/basic")
+	if status != http.StatusOK || !strings.Contains(body, `name="/name"`) || !strings.Contains(body, "jsonforms-overlay") ||
+		!strings.Contains(body, "unresolved scope") {
+		t.Errorf("expected the last screen with the errors, got %d:\n%s", status, body)
+	}
+
+	// fixed files drop the overlay
+	write("basic/uischema.json", `{"type": "VerticalLayout", "elements": [{"type": "Control", "scope": "#/properties/name"}]}`)
+	d.check()
+	if _, body := get("/basic"); strings.Contains(body, "jsonforms-overlay") {
+		t.Errorf("expected no overlay, got:\n%s", body)
+	}
+}
+
+func TestDevServerWithoutScreens(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	d := newDevServer(os.DirFS(t.TempDir()), &stdout, &stderr)
+
+	w := httptest.NewRecorder()
+	d.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "no screen folder found") ||
+		!strings.Contains(w.Body.String(), reloadScript) {
+		t.Errorf("expected the overlay, got %d:\n%s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(stderr.String(), "build failed") {
+		t.Errorf("expected the failed build in the log, got %q", stderr.String())
+	}
+}
+
+func TestDevServerRenderError(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("schema.json", `{"properties": {"people": {"type": "array-select", "items": {"type": "object", "properties": {"name": {"type": "string"}}}}}}`)
+	write("uischema.json", `{"type": "Control", "scope": "#/properties/people", "options": {"elementLabelProp": "name"}}`)
+	write("data.json", `{"people": [{"nickname": "Jo"}]}`)
+
+	var stdout, stderr bytes.Buffer
+	d := newDevServer(os.DirFS(filepath.Dir(dir)), &stdout, &stderr)
+
+	// the data binds only when rendered
+	w := httptest.NewRecorder()
+	d.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/"+filepath.Base(dir), nil))
+	body := w.Body.String()
+	if w.Code != http.StatusInternalServerError || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") ||
+		!strings.Contains(body, "jsonforms-overlay") || !strings.Contains(body, "no label prop") || !strings.HasSuffix(body, reloadScript) {
+		t.Errorf("expected the overlay with the render error, got %d:\n%s", w.Code, body)
+	}
+}
@@ -0,0 +1,63 @@
+package gojsonforms_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+	"github.com/TobiEiss/go-jsonforms/models"
+)
+
+func TestConfirmation(t *testing.T) {
+	f, err := gojsonforms.NewBuilder().
+		WithSchemaBytes([]byte(`{"properties": {"name": {"type": "string"}}}`)).
+		WithConfirmation(models.Confirmation{
+			ButtonText: "Save",
+			Title:      "Save `now`?",
+			Body:       "Save {/name}? ${alert(1)}</div><script>alert(1)</script>",
+			Confirm:    "Yes",
+			Cancel:     "No",
+			Summary:    true,
+		}).
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := f.Render(context.Background(), &sb, gojsonforms.RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	page := sb.String()
+	for _, expected := range []string{
+		`<dialog id="jsonforms-confirm"`,
+		`<button class="btn" type="submit" data-jsonforms-confirm="jsonforms-confirm">Save</button>`,
+		`Save <span data-jsonforms-value="/name"></span>? ${alert(1)}&lt;/div&gt;&lt;script&gt;alert(1)&lt;/script&gt;`,
+		`<dl class="jsonforms-summary" data-jsonforms-summary></dl>`,
+	} {
+		if !strings.Contains(page, expected) {
+			t.Errorf("expected %s in:\n%s", expected, page)
+		}
+	}
+	if strings.Contains(page, "<script>alert(1)") || strings.Contains(page, "htmx-internal-data") {
+		t.Errorf("unexpected confirmation in:\n%s", page)
+	}
+
+	// fragments and raw forms carry the dialog inside the form, so swaps
+	// never leave a button without its dialog
+	sb.Reset()
+	if err := f.RenderFragment(context.Background(), &sb, gojsonforms.RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := f.Build(false, gojsonforms.RenderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, html := range []string{page, sb.String(), raw} {
+		dialog := strings.Index(html, `<dialog id="jsonforms-confirm"`)
+		if dialog < 0 || dialog > strings.Index(html, "</form>") || strings.Count(html, "<dialog") != 1 {
+			t.Errorf("expected the dialog inside the form in:\n%s", html)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package gojsonforms_test
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+)
+
+func TestCSP(t *testing.T) {
+	fsys := fstest.MapFS{
+		"select/schema.json": {Data: []byte(`{"properties": {"people": {"type": "array", "items": {"properties": {"name": {"type": "string"}}}}}}`)},
+		"select/uischema.json": {Data: []byte(`{"type": "VerticalLayout", "elements": [
+			{"type": "Control", "scope": "#/properties/people", "options": {"select": true, "elementLabelProp": "name"}}]}`)},
+		"select/data.json": {Data: []byte(`{"people": [{"name": "Jane"}]}`)},
+	}
+	app, err := gojsonforms.NewApp(fsys).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	gojsonforms.CSP(app).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/select", nil))
+	policy := w.Header().Get("Content-Security-Policy")
+	nonce := regexp.MustCompile(`'nonce-([^']+)'`).FindStringSubmatch(policy)
+	if nonce == nil || strings.Contains(policy, "unsafe-inline") {
+		t.Fatalf("unexpected policy %q", policy)
+	}
+
+	page := html.UnescapeString(w.Body.String())
+	if strings.Contains(page, "onchange=") || strings.Contains(page, "innerHTML") {
+		t.Errorf("expected no inline handlers in:\n%s", page)
+	}
+	if !strings.Contains(page, `<script src="/_jsonforms/jsonforms.js?v=`) {
+		t.Errorf("expected the external script in:\n%s", page)
+	}
+	for _, tag := range regexp.MustCompile(`<(script|style)[^>]*>`).FindAllString(page, -1) {
+		if !strings.Contains(tag, ` src="`) && !strings.Contains(tag, ` nonce="`+nonce[1]+`"`) {
+			t.Errorf("inline tag without nonce: %s", tag)
+		}
+	}
+
+	// a new nonce per request
+	w = httptest.NewRecorder()
+	gojsonforms.CSP(app).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/select", nil))
+	if w.Header().Get("Content-Security-Policy") == policy {
+		t.Error("expected a new nonce")
+	}
+}
+
+func TestInlineScriptNonce(t *testing.T) {
+	f, err := gojsonforms.NewBuilder().
+		WithSchemaBytes([]byte(`{"properties": {"name": {"type": "string"}}}`)).
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := f.Render(context.Background(), &sb, gojsonforms.RenderOptions{Nonce: "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), `<script nonce="abc">`) || !strings.Contains(sb.String(), `function arraySelect(select)`) {
+		t.Errorf("expected the script inline with nonce in:\n%s", sb.String())
+	}
+}
@@ -0,0 +1,140 @@
+package gojsonforms
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+)
+
+const (
+	// CSRFField is the form field the token against cross-site request
+	// forgery is submitted in.
+	CSRFField = form.CSRFField
+	// CSRFHeader is the request header htmx submits the token in.
+	CSRFHeader = "X-CSRF-Token"
+	// CSRFCookie is the cookie of the default CSRF protection.
+	CSRFCookie = "jsonforms_csrf"
+)
+
+// ErrCSRF reports a submit without valid token against cross-site request
+// forgery.
+var ErrCSRF = errors.New("invalid csrf token")
+
+// CSRF issues the tokens submitted with the forms and checks them. Forms get
+// the token as hidden input and htmx header, custom templates include it with
+// {{ template "CSRF" . }}.
+type CSRF interface {
+	// Token returns the token for the forms of the response to r. It may set
+	// headers of w, like a cookie, and is called before anything is written.
+	Token(w http.ResponseWriter, r *http.Request) (string, error)
+	// Verify checks the token submitted with r, reporting ErrCSRF.
+	Verify(r *http.Request, token string) error
+}
+
+// ErrCSRFKey is returned by NewCSRF for keys shorter than 32 bytes.
+var ErrCSRFKey = errors.New("csrf key too short")
+
+// NewCSRF returns the default protection, a signed double-submit cookie: a
+// random cookie per browser and, as token, its HMAC with key. Sites can't
+// compute the token without the key, even if they manage to set the cookie.
+// All instances have to share the key, which needs at least 32 random bytes.
+func NewCSRF(key []byte, opts ...CSRFOption) (CSRF, error) {
+	if len(key) < minCSRFKey {
+		return nil, fmt.Errorf("%w: %d bytes, need at least %d", ErrCSRFKey, len(key), minCSRFKey)
+	}
+	c := &doubleSubmit{key: bytes.Clone(key)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// minCSRFKey is the shortest key accepted by NewCSRF, as long as the HMAC.
+const minCSRFKey = sha256.Size
+
+// CSRFOption configures the protection of NewCSRF.
+type CSRFOption func(*doubleSubmit)
+
+// CSRFSecureCookie marks the cookie Secure for every request. Without it the
+// cookie is Secure for requests over TLS only, which behind a proxy that
+// terminates TLS are none.
+func CSRFSecureCookie() CSRFOption {
+	return func(c *doubleSubmit) {
+		c.secure = true
+	}
+}
+
+type doubleSubmit struct {
+	key    []byte
+	secure bool
+}
+
+func (c *doubleSubmit) Token(w http.ResponseWriter, r *http.Request) (string, error) {
+	secret, err := c.secret(r)
+	if err != nil {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return "", err
+		}
+		http.SetCookie(w, &http.Cookie{
+			Name:     CSRFCookie,
+			Value:    base64.RawURLEncoding.EncodeToString(secret),
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   c.secure || r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	return c.sign(secret), nil
+}
+
+func (c *doubleSubmit) Verify(r *http.Request, token string) error {
+	secret, err := c.secret(r)
+	if err != nil || token == "" || !hmac.Equal([]byte(token), []byte(c.sign(secret))) {
+		return ErrCSRF
+	}
+	return nil
+}
+
+// secret reads the secret of the browser from its cookie.
+func (c *doubleSubmit) secret(r *http.Request) ([]byte, error) {
+	cookie, err := r.Cookie(CSRFCookie)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil || len(secret) != 32 {
+		return nil, ErrCSRF
+	}
+	return secret, nil
+}
+
+func (c *doubleSubmit) sign(secret []byte) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(secret)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+type csrfTokenKey struct{}
+
+func contextWithCSRFToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, csrfTokenKey{}, token)
+}
+
+// submittedCSRFToken reads the token of a submit, from the htmx header or
+// else the submitted values.
+func submittedCSRFToken(r *http.Request, values url.Values) string {
+	if token := r.Header.Get(CSRFHeader); token != "" {
+		return token
+	}
+	return values.Get(CSRFField)
+}
@@ -0,0 +1,134 @@
+package gojsonforms_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+)
+
+func TestCSRF(t *testing.T) {
+	handler, _ := newTestHandler(t)
+	csrf, err := gojsonforms.NewCSRF([]byte(strings.Repeat("k", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler.WithCSRF(csrf)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != gojsonforms.CSRFCookie || !cookies[0].HttpOnly {
+		t.Fatalf("expected csrf cookie, got %v", cookies)
+	}
+	match := regexp.MustCompile(`<input type="hidden" name="_csrf" value="([^"]+)">`).FindStringSubmatch(w.Body.String())
+	if match == nil || !strings.Contains(w.Body.String(), `hx-headers='{"X-CSRF-Token": "`+match[1]+`"}'`) {
+		t.Fatalf("expected token in form:\n%s", w.Body.String())
+	}
+	token := match[1]
+
+	submit := func(values url.Values, header string, cookie *http.Cookie) int {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("HX-Request", "true")
+		if header != "" {
+			r.Header.Set(gojsonforms.CSRFHeader, header)
+		}
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	other, err := gojsonforms.NewCSRF([]byte(strings.Repeat("o", 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	otherToken, _ := other.Token(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	otherCookie := w.Result().Cookies()[0]
+
+	tests := []struct {
+		name   string
+		values url.Values
+		header string
+		cookie *http.Cookie
+		code   int
+	}{
+		{"form field", url.Values{"/name": {"John"}, "_csrf": {token}}, "", cookies[0], http.StatusNoContent},
+		{"header", url.Values{"/name": {"John"}}, token, cookies[0], http.StatusNoContent},
+		{"no token", url.Values{"/name": {"John"}}, "", cookies[0], http.StatusForbidden},
+		{"no cookie", url.Values{"/name": {"John"}, "_csrf": {token}}, "", nil, http.StatusForbidden},
+		{"wrong token", url.Values{"/name": {"John"}, "_csrf": {token + "x"}}, "", cookies[0], http.StatusForbidden},
+		{"token of another key", url.Values{"/name": {"John"}, "_csrf": {otherToken}}, "", otherCookie, http.StatusForbidden},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if code := submit(test.values, test.header, test.cookie); code != test.code {
+				t.Errorf("expected %d, got %d", test.code, code)
+			}
+		})
+	}
+}
+
+func TestCSRFCustomTemplates(t *testing.T) {
+	templates := fstest.MapFS{
+		"tpl/index.html": {Data: []byte(`<form>{{ template "CSRF" . }}</form>`)},
+	}
+	f, err := gojsonforms.NewBuilder().
+		WithSchemaFile("testdata/basic/schema.json").
+		WithCustomTemplateFS("tpl", templates).
+		WithTemplateCacheKey("TestCSRFCustomTemplates").
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	html, err := f.Build(true, gojsonforms.RenderOptions{CSRFToken: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html != "<form>\n"+`<input type="hidden" name="_csrf" value="abc"></form>` {
+		t.Errorf("unexpected html %q", html)
+	}
+}
+
+func TestCSRFKey(t *testing.T) {
+	for _, key := range [][]byte{nil, {}, []byte("key")} {
+		if _, err := gojsonforms.NewCSRF(key); !errors.Is(err, gojsonforms.ErrCSRFKey) {
+			t.Errorf("expected ErrCSRFKey for the key %q, got %v", key, err)
+		}
+	}
+}
+
+func TestCSRFSecureCookie(t *testing.T) {
+	key := []byte(strings.Repeat("k", 32))
+	for _, test := range []struct {
+		opts   []gojsonforms.CSRFOption
+		secure bool
+	}{
+		{nil, false},
+		{[]gojsonforms.CSRFOption{gojsonforms.CSRFSecureCookie()}, true},
+	} {
+		csrf, err := gojsonforms.NewCSRF(key, test.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// behind a proxy terminating TLS
+		w := httptest.NewRecorder()
+		if _, err := csrf.Token(w, httptest.NewRequest(http.MethodGet, "/", nil)); err != nil {
+			t.Fatal(err)
+		}
+		if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].Secure != test.secure {
+			t.Errorf("expected a cookie with Secure %v, got %v", test.secure, cookies)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package gojsonforms
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+)
+
+// VerifyInto verifies submitted values like Verify and decodes the data into
+// a T, mapping the JSON tags of its fields:
+//
+//	settings, err := gojsonforms.VerifyInto[Settings](form, r.PostForm)
+func VerifyInto[T any](f *Form, values url.Values) (T, error) {
+	data, err := f.Verify(values)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return Decode[T](data)
+}
+
+// Decode decodes verified data, e.g. in a SubmitFunc, into a T. Values that
+// don't fit their field are reported like the errors of Verify, as FormError
+// with the data pointer of the value wrapping a ValidationError.
+func Decode[T any](data map[string]interface{}) (T, error) {
+	var v T
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return v, &FormError{Stage: StageVerify, Err: fmt.Errorf("%w: %v", ErrInvalidData, err)}
+	}
+
+	err = json.Unmarshal(raw, &v)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		// json only reports the first mismatch by its dotted field path, so
+		// walk the data along T to report each value at its data pointer
+		var structure interface{}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		if decoder.Decode(&structure) == nil {
+			if errs := mismatches(structure, reflect.TypeOf(v), nil); len(errs) > 0 {
+				return v, errors.Join(errs...)
+			}
+		}
+		return v, &FormError{Stage: StageVerify, Err: &ValidationError{Message: "must be " + describe(typeErr.Type)}}
+	}
+	if err != nil {
+		return v, &FormError{Stage: StageVerify, Err: fmt.Errorf("%w: %v", ErrInvalidData, err)}
+	}
+	return v, nil
+}
+
+var (
+	jsonUnmarshaler = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// mismatches returns an error for each value below tokens that json can't
+// decode into a t.
+func mismatches(value interface{}, t reflect.Type, tokens []string) []error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if value == nil || t.Kind() == reflect.Interface ||
+		reflect.PointerTo(t).Implements(jsonUnmarshaler) || reflect.PointerTo(t).Implements(textUnmarshaler) {
+		return nil
+	}
+
+	mismatch := []error{&FormError{
+		Stage: StageVerify,
+		Scope: form.Pointer(tokens),
+		Err:   &ValidationError{Message: "must be " + describe(t)},
+	}}
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch
+		}
+		var errs []error
+		for _, key := range slices.Sorted(maps.Keys(object)) {
+			if field, ok := jsonField(t, key); ok {
+				errs = append(errs, mismatches(object[key], field, append(slices.Clip(tokens), key))...)
+			}
+		}
+		return errs
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch
+		}
+		var errs []error
+		for _, key := range slices.Sorted(maps.Keys(object)) {
+			errs = append(errs, mismatches(object[key], t.Elem(), append(slices.Clip(tokens), key))...)
+		}
+		return errs
+	case reflect.Slice, reflect.Array:
+		if _, ok := value.(string); ok && t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			return mismatch
+		}
+		var errs []error
+		for i, item := range list {
+			errs = append(errs, mismatches(item, t.Elem(), append(slices.Clip(tokens), strconv.Itoa(i)))...)
+		}
+		return errs
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			return mismatch
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			return mismatch
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := value.(json.Number)
+		if !ok {
+			return mismatch
+		}
+		if i, err := strconv.ParseInt(n.String(), 10, 64); err != nil || reflect.Zero(t).OverflowInt(i) {
+			return mismatch
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := value.(json.Number)
+		if !ok {
+			return mismatch
+		}
+		if u, err := strconv.ParseUint(n.String(), 10, 64); err != nil || reflect.Zero(t).OverflowUint(u) {
+			return mismatch
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(json.Number); !ok {
+			return mismatch
+		}
+	}
+	return nil
+}
+
+// jsonField returns the type of the field json decodes key into, preferring
+// an exact match of its name over a case-insensitive one like json does.
+func jsonField(t reflect.Type, key string) (reflect.Type, bool) {
+	var folded reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if typ, ok := jsonField(embedded, key); ok {
+					return typ, true
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		typ := field.Type
+		if slices.Contains(strings.Split(opts, ","), "string") {
+			// quoted values are left to json
+			typ = reflect.TypeOf((*interface{})(nil)).Elem()
+		}
+		if name == key {
+			return typ, true
+		}
+		if folded == nil && strings.EqualFold(name, key) {
+			folded = typ
+		}
+	}
+	return folded, folded != nil
+}
+
+// describe names the JSON values a Go type takes, like the messages of
+// Validate.
+func describe(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	}
+	return "an object"
+}
@@ -0,0 +1,80 @@
+package gojsonforms_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+)
+
+type person struct {
+	Name    string `json:"name"`
+	Age     int    `json:"age"`
+	Address struct {
+		Zip int `json:"zip code"`
+	} `json:"address"`
+}
+
+func TestDecode(t *testing.T) {
+	f, err := gojsonforms.NewBuilder().
+		WithSchemaBytes([]byte(`{
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string"},
+				"age": {"type": "integer"},
+				"address": {"type": "object", "properties": {"zip code": {"type": "string"}}}
+			}
+		}`)).
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := gojsonforms.VerifyInto[person](f, url.Values{"/name": {"John"}, "/age": {"42"}})
+	if err != nil || p.Name != "John" || p.Age != 42 {
+		t.Errorf("unexpected %+v, %v", p, err)
+	}
+
+	if _, err := gojsonforms.VerifyInto[person](f, url.Values{"/age": {"42"}}); !errors.Is(err, gojsonforms.ErrValidation) {
+		t.Errorf("expected the errors of Verify, got %v", err)
+	}
+
+	// the schema allows strings the struct can't take
+	_, err = gojsonforms.VerifyInto[person](f, url.Values{"/name": {"John"}, "/address/zip code": {"12a"}})
+	expected := map[string][]string{"/address/zip code": {"must be an integer"}}
+	if !errors.Is(err, gojsonforms.ErrValidation) || !reflect.DeepEqual(gojsonforms.FieldErrors(err), expected) {
+		t.Errorf("expected field error %v, got %v", expected, err)
+	}
+
+	if _, err := gojsonforms.Decode[[]string](map[string]interface{}{"name": "John"}); !errors.Is(err, gojsonforms.ErrValidation) {
+		t.Errorf("expected error for data that is no list, got %v", err)
+	}
+}
+
+func TestDecodeMismatches(t *testing.T) {
+	type settings struct {
+		Limits  map[string]int `json:"a.b"`
+		Enabled bool           `json:"enabled"`
+		Tags    []string       `json:"tags"`
+		Size    uint8          `json:"size"`
+	}
+
+	_, err := gojsonforms.Decode[settings](map[string]interface{}{
+		"a.b":     map[string]interface{}{"x": "1", "y": 2},
+		"enabled": "yes",
+		"tags":    []interface{}{"go", 3},
+		"size":    300,
+	})
+	expected := map[string][]string{
+		"/a.b/x":   {"must be an integer"},
+		"/enabled": {"must be true or false"},
+		"/tags/1":  {"must be a string"},
+		"/size":    {"must be an integer"},
+	}
+	if !errors.Is(err, gojsonforms.ErrValidation) || !reflect.DeepEqual(gojsonforms.FieldErrors(err), expected) {
+		t.Errorf("expected field errors %v, got %v", expected, gojsonforms.FieldErrors(err))
+	}
+}
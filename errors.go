@@ -0,0 +1,71 @@
+package gojsonforms
+
+import "github.com/TobiEiss/go-jsonforms/internal/form"
+
+// FormError is reported by every stage of building, rendering and verifying a
+// form. It carries the Stage, the affected Scope (if any) and the cause, which
+// wraps one of the Err* sentinel errors:
+//
+//	var formErr *gojsonforms.FormError
+//	if errors.As(err, &formErr) && errors.Is(err, gojsonforms.ErrUnresolvedScope) {
+//		log.Printf("fix scope %s", formErr.Scope)
+//	}
+type FormError = form.FormError
+
+// Stage names the step of the form pipeline a FormError occurred in.
+type Stage = form.Stage
+
+const (
+	StageRead     = form.StageRead
+	StageValidate = form.StageValidate
+	StageSetup    = form.StageSetup
+	StageBind     = form.StageBind
+	StageRender   = form.StageRender
+	StageVerify   = form.StageVerify
+)
+
+var (
+	// ErrNoSchema is returned if a form is built without schema.
+	ErrNoSchema = form.ErrNoSchema
+	// ErrMissingScope is returned if a Control has no scope.
+	ErrMissingScope = form.ErrMissingScope
+	// ErrInvalidScope is returned if a scope is no valid JSON pointer.
+	ErrInvalidScope = form.ErrInvalidScope
+	// ErrUnresolvedScope is returned if a scope does not resolve in the schema.
+	ErrUnresolvedScope = form.ErrUnresolvedScope
+	// ErrUnknownElementType is returned for UI schema elements of unknown type.
+	ErrUnknownElementType = form.ErrUnknownElementType
+	// ErrInvalidUISchema is returned if the UI schema is malformed.
+	ErrInvalidUISchema = form.ErrInvalidUISchema
+	// ErrUnresolvedRef is reported by the lint of the jsonforms command for a
+	// $ref that does not resolve in the schema.
+	ErrUnresolvedRef = form.ErrUnresolvedRef
+	// ErrInvalidData is returned if data doesn't fit the form.
+	ErrInvalidData = form.ErrInvalidData
+	// ErrValidation is returned for submitted values that don't match the
+	// schema, see ValidationError.
+	ErrValidation = form.ErrValidation
+	// ErrTemplate is returned if a template can't be parsed or executed.
+	ErrTemplate = form.ErrTemplate
+	// ErrTooLarge is returned by VerifyRequest for bodies over the size limit.
+	ErrTooLarge = form.ErrTooLarge
+)
+
+// UISchemaError is returned by Build if the UI schema is malformed or one of
+// its scopes does not resolve in the schema. It lists every problem found and
+// matches ErrInvalidUISchema as well as the sentinels of its problems.
+type UISchemaError = form.UISchemaError
+
+// UISchemaProblem is a single problem of a UISchemaError, located by the
+// JSON pointer of the UI schema element.
+type UISchemaProblem = form.Problem
+
+// ValidationError is the cause of a FormError for a submitted value that
+// doesn't match the schema. The Scope of the FormError is the data pointer
+// of the value.
+type ValidationError = form.ValidationError
+
+// FieldErrors collects the messages of the FormError values in err by scope.
+func FieldErrors(err error) map[string][]string {
+	return form.FieldErrors(err)
+}
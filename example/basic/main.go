@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -18,35 +19,27 @@ var (
 )
 
 func main() {
+	form, err := gojsonforms.NewBuilder().
+		WithSchemaFile(schema).
+		WithUISchemaFile(uiSchema).
+		WithDataFile(data).
+		WithAssets("/assets").
+		Compile()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	router := chi.NewRouter()
 	router.Use(middleware.Logger)
-	router.Get("/", func(w http.ResponseWriter, r *http.Request) {
-
-		html, err := gojsonforms.NewBuilder().
-			WithSchemaFile(schema).
-			WithUISchemaFile(uiSchema).
-			WithDataFile(data).
-			Build(true)
-		if err != nil {
-			fmt.Println("Error:", err.Error())
-		}
-
-		fmt.Fprintf(w, html)
-	})
-	router.Post("/", func(w http.ResponseWriter, r *http.Request) {
-		err := r.ParseForm()
-		if err != nil {
-			panic(err)
-		}
-
-		result := gojsonforms.Verify(r.Form)
-		jsonData, err := json.MarshalIndent(result, "", "  ")
+	router.Handle("/assets/*", http.StripPrefix("/assets", gojsonforms.AssetHandler()))
+	router.Handle("/", gojsonforms.NewHandler(form).OnSubmit(func(ctx context.Context, data map[string]interface{}) error {
+		jsonData, err := json.MarshalIndent(data, "", "  ")
 		if err != nil {
-			fmt.Println("Error marshaling JSON:", err)
-			return
+			return err
 		}
 		fmt.Println(string(jsonData))
-	})
+		return nil
+	}))
 
 	log.Fatal(http.ListenAndServe("localhost:8080", router))
 }
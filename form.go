@@ -0,0 +1,283 @@
+package gojsonforms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	gabs "github.com/Jeffail/gabs/v2"
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+	"github.com/TobiEiss/go-jsonforms/models"
+)
+
+// Form is a form compiled from schema and UI schema. It is immutable, so
+// compile it once and render it with per-request data from many goroutines.
+type Form struct {
+	form     *form.Form
+	page     form.Page
+	data     *gabs.Container
+	maxBytes int64
+	logger   *slog.Logger
+}
+
+// RenderOptions are the per-request settings for rendering a compiled Form.
+// Empty fields fall back to the settings of the builder.
+type RenderOptions struct {
+	// Data is bound to the form. It is a map, JSON bytes or any value
+	// marshalling to a JSON object.
+	Data interface{}
+	// Menu replaces the menu of the builder, e.g. to mark the current item.
+	Menu []models.MenuItem
+	// Errors returned by Verify are shown next to the controls of the values.
+	Errors error
+	// BasePath replaces the base path of the builder and the one of the
+	// request context (see Mount).
+	BasePath string
+	// Nonce replaces the nonce of the request context (see CSP).
+	Nonce string
+	// CSRFToken is submitted with the form, see CSRF. The Handler sets it.
+	CSRFToken string
+}
+
+// Compile reads schema, UI schema and data once and prepares the form for
+// rendering. The data of the builder becomes the default data of the form.
+func (b *builder) Compile() (*Form, error) {
+	logger := b.logger()
+	start := time.Now()
+
+	// schema is necessary
+	schema, err := b.schema.Read()
+	if err != nil {
+		return nil, &FormError{Stage: StageRead, Err: fmt.Errorf("schema: %w", err)}
+	}
+	if schema == nil {
+		return nil, &FormError{Stage: StageRead, Err: ErrNoSchema}
+	}
+
+	// uischema is optional - generate default if not provided
+	uiSchema, err := b.uiSchema.Read()
+	if err != nil {
+		return nil, &FormError{Stage: StageRead, Err: fmt.Errorf("uischema: %w", err)}
+	}
+
+	// if no uiSchema provided, generate default from schema
+	if uiSchema == nil {
+		uiSchema, err = generateDefaultUISchema(schema)
+		if err != nil {
+			return nil, &FormError{Stage: StageSetup, Err: err}
+		}
+		logger.Debug("default uischema generated", "elements", len(uiSchema.Search("elements").Children()))
+	}
+
+	// every scope must resolve in the schema
+	if err := form.ValidateUISchema(schema, uiSchema); err != nil {
+		return nil, &FormError{Stage: StageValidate, Err: err}
+	}
+
+	var f *form.Form
+	if b.useCustomTemplates {
+		opts := []form.Option{form.WithLogger(logger)}
+		if b.templateCacheKey != "" {
+			opts = append(opts, form.WithTemplateKey(b.templateCacheKey))
+		}
+		f, err = form.NewFormWithCustomTemplates(schema, uiSchema, b.customTemplateFS, b.customTemplateDir, b.useCustomTemplates, opts...)
+	} else {
+		f, err = form.NewForm(schema, uiSchema, form.WithLogger(logger))
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.SetCustomTemplateExt(b.customTemplateExt)
+
+	data, err := b.data.Read()
+	if err != nil {
+		return nil, &FormError{Stage: StageRead, Err: fmt.Errorf("data: %w", err)}
+	}
+
+	actions := b.actions
+	if len(actions) == 0 {
+		actions = defaultActions(b.confirmation)
+	}
+
+	logger.Debug("form compiled", "duration", time.Since(start))
+	return &Form{
+		form: f,
+		page: form.Page{
+			Menu:         b.menu,
+			BasePath:     form.CleanBasePath(b.basePath),
+			AssetsPath:   b.assetsPath,
+			PostLink:     b.postLink,
+			CssPath:      b.cssPath,
+			LogoPath:     b.logoPath,
+			Confirmation: b.confirmation,
+			Actions:      actions,
+		},
+		maxBytes: b.maxRequestBytes,
+		data:     data,
+		logger:   logger,
+	}, nil
+}
+
+// Build renders the form with the per-request options, as full page
+// (withIndex) or content only.
+func (f *Form) Build(withIndex bool, opts RenderOptions) (string, error) {
+	var builder strings.Builder
+	entry := form.EntryContent
+	if withIndex {
+		entry = form.EntryIndex
+	}
+	err := f.render(context.Background(), &builder, entry, opts)
+	return builder.String(), err
+}
+
+// Render writes the form as full page to w, e.g. an http.ResponseWriter.
+// On template errors, part of the page may have been written.
+func (f *Form) Render(ctx context.Context, w io.Writer, opts RenderOptions) error {
+	return f.render(ctx, w, form.EntryIndex, opts)
+}
+
+// RenderFragment writes the form without the surrounding page to w, e.g. to
+// answer htmx requests.
+func (f *Form) RenderFragment(ctx context.Context, w io.Writer, opts RenderOptions) error {
+	return f.render(ctx, w, form.EntryContent, opts)
+}
+
+func (f *Form) render(ctx context.Context, w io.Writer, entry string, opts RenderOptions) error {
+	start := time.Now()
+
+	data, err := f.bindData(opts)
+	if err != nil {
+		return err
+	}
+
+	page := f.page
+	if opts.Menu != nil {
+		page.Menu = opts.Menu
+	}
+	if opts.BasePath != "" {
+		page.BasePath = form.CleanBasePath(opts.BasePath)
+	} else {
+		page.BasePath = f.basePath(ctx)
+	}
+	if opts.Nonce != "" {
+		page.Nonce = opts.Nonce
+	} else if nonce, ok := NonceFromContext(ctx); ok {
+		page.Nonce = nonce
+	}
+	if opts.CSRFToken != "" {
+		page.CSRFToken = opts.CSRFToken
+	} else if token, ok := ctx.Value(csrfTokenKey{}).(string); ok {
+		page.CSRFToken = token
+	}
+
+	err = f.form.Render(ctx, w, form.RenderInput{Entry: entry, Data: data, Errors: opts.Errors, Page: page})
+	f.logger.DebugContext(ctx, "form built", "entry", entry, "duration", time.Since(start))
+	return err
+}
+
+// Tree binds the data of opts to a copy of the compiled tree, for custom
+// renderers.
+func (f *Form) Tree(opts RenderOptions) (Node, error) {
+	data, err := f.bindData(opts)
+	if err != nil {
+		return nil, err
+	}
+	return f.form.BindWithErrors(data, opts.Errors)
+}
+
+// Verify reads submitted form values into data typed by the schema and
+// validates it. The data is returned also on errors, to render it again with
+// RenderOptions.Errors. Values the user has to fix are reported as
+// ErrValidation. Draft actions skip the checks of required values.
+func (f *Form) Verify(values url.Values) (map[string]interface{}, error) {
+	data, err := f.form.Decode(values)
+	return f.validate(values, data, err)
+}
+
+// Validate checks data, e.g. read from a JSON file or API instead of a
+// submitted form, against the schema. Data is a map, JSON bytes or any value
+// marshalling to a JSON object. Findings are reported like those of Verify.
+func (f *Form) Validate(data interface{}) error {
+	c, err := toContainer(data)
+	if err != nil {
+		return &FormError{Stage: StageVerify, Err: fmt.Errorf("%w: %v", ErrInvalidData, err)}
+	}
+	return f.form.Validate(c)
+}
+
+// UISchema returns the UI schema of the form as JSON, the generated default
+// one if the builder had none.
+func (f *Form) UISchema() []byte {
+	return f.form.UISchema()
+}
+
+// validate checks decoded data for the action of values, joined with the
+// errors of decoding.
+func (f *Form) validate(values url.Values, data *gabs.Container, err error) (map[string]interface{}, error) {
+	action, aerr := f.Action(values)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	validate := f.form.Validate
+	if action.Draft {
+		validate = f.form.ValidateDraft
+	}
+	if verr := validate(data); verr != nil {
+		err = errors.Join(err, verr)
+	}
+	m, _ := data.Data().(map[string]interface{})
+	return m, err
+}
+
+// basePath returns the base path of the request context or else the one of
+// the builder.
+func (f *Form) basePath(ctx context.Context) string {
+	if base, ok := BasePathFromContext(ctx); ok {
+		return base
+	}
+	return f.page.BasePath
+}
+
+// link resolves a link of the page for the request of ctx.
+func (f *Form) link(ctx context.Context, link string) string {
+	return form.Link(f.basePath(ctx), link)
+}
+
+// bindData returns the data of opts or else the data of the builder.
+func (f *Form) bindData(opts RenderOptions) (*gabs.Container, error) {
+	if opts.Data == nil {
+		return f.data, nil
+	}
+	data, err := toContainer(opts.Data)
+	if err != nil {
+		return nil, &FormError{Stage: StageBind, Err: fmt.Errorf("%w: %v", ErrInvalidData, err)}
+	}
+	return data, nil
+}
+
+// toContainer wraps data given as map, JSON bytes or marshallable value.
+func toContainer(data interface{}) (*gabs.Container, error) {
+	switch d := data.(type) {
+	case *gabs.Container:
+		return d, nil
+	case map[string]interface{}:
+		return gabs.Wrap(d), nil
+	case []byte:
+		return gabs.ParseJSON(d)
+	case json.RawMessage:
+		return gabs.ParseJSON(d)
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return gabs.ParseJSON(b)
+}
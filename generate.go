@@ -0,0 +1,42 @@
+package gojsonforms
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/TobiEiss/go-jsonforms/internal/schema"
+)
+
+// SchemaTag is the struct tag GenerateSchema reads besides json, e.g.
+//
+//	Name string `json:"name" jsonschema:"title=Name,min=3"`
+//	Role string `json:"role,omitempty" jsonschema:"enum=admin|user,default=user"`
+//
+// It takes title, description, format, pattern, enum, default, min, max,
+// readonly and required.
+const SchemaTag = schema.Tag
+
+// GenerateSchema generates the JSON schema of the struct type of v (a value,
+// pointer or reflect.Type) and a starter UI schema with a control per field
+// in field order and a group per nested struct. Both can be passed to
+// WithSchemaMap and WithUISchemaMap:
+//
+//	schema, uiSchema, err := gojsonforms.GenerateSchema(Settings{})
+//
+// Fields are named by their json tag, fields without omitempty are
+// required, pointers are nullable and time.Time is a date-time string.
+func GenerateSchema(v interface{}) (jsonSchema, uiSchema map[string]interface{}, err error) {
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	if t == nil {
+		return nil, nil, &FormError{Stage: StageRead, Err: errors.New("no type to generate a schema of")}
+	}
+
+	jsonSchema, uiSchema, err = schema.Generate(t)
+	if err != nil {
+		return nil, nil, &FormError{Stage: StageRead, Err: err}
+	}
+	return jsonSchema, uiSchema, nil
+}
@@ -0,0 +1,60 @@
+package gojsonforms_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+)
+
+type settings struct {
+	Name       string    `json:"name" jsonschema:"title=Name,min=3"`
+	Role       string    `json:"role,omitempty" jsonschema:"title=Role,enum=admin|user"`
+	Age        *int      `json:"age,omitempty"`
+	Newsletter bool      `json:"newsletter"`
+	Since      time.Time `json:"since,omitempty"`
+	Address    struct {
+		City string `json:"city"`
+	} `json:"address"`
+}
+
+func TestGenerateSchema(t *testing.T) {
+	schema, uiSchema, err := gojsonforms.GenerateSchema(settings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := gojsonforms.NewBuilder().
+		WithSchemaMap(schema).
+		WithUISchemaMap(uiSchema).
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := f.RenderFragment(context.Background(), &sb, gojsonforms.RenderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`name="/name"`, `<option value="admin"`, `name="/age" type="number"`, `type="checkbox"`, `type="datetime-local"`, `name="/address/city"`} {
+		if !strings.Contains(sb.String(), expected) {
+			t.Errorf("expected %s in:\n%s", expected, sb.String())
+		}
+	}
+
+	s, err := gojsonforms.VerifyInto[settings](f, url.Values{"/name": {"John"}, "/age": {"42"}, "/since": {"2024-05-01T10:30"}, "/address/city": {"Berlin"}})
+	if err != nil || *s.Age != 42 || s.Address.City != "Berlin" || !s.Since.Equal(time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("unexpected %+v, %v", s, err)
+	}
+	if _, err := gojsonforms.VerifyInto[settings](f, url.Values{"/name": {"Jo"}}); !errors.Is(err, gojsonforms.ErrValidation) {
+		t.Errorf("expected validation errors, got %v", err)
+	}
+
+	if _, _, err := gojsonforms.GenerateSchema(nil); err == nil {
+		t.Error("expected error without type")
+	}
+}
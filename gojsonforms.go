@@ -1,8 +1,13 @@
 package gojsonforms
 
 import (
+	"context"
 	"embed"
+	"io"
+	"io/fs"
+	"log/slog"
 	"net/url"
+	"sort"
 
 	gabs "github.com/Jeffail/gabs/v2"
 	"github.com/TobiEiss/go-jsonforms/internal/form"
@@ -21,13 +26,19 @@ type builder struct {
 	data               reader
 	menu               []models.MenuItem
 	postLink           string
+	basePath           string
+	assetsPath         string
 	cssPath            string
 	logoPath           string
 	confirmation       models.Confirmation
-	customTemplateFS   embed.FS
+	actions            []models.Action
+	maxRequestBytes    int64
+	customTemplateFS   fs.FS
 	customTemplateDir  string
+	templateCacheKey   string
 	useCustomTemplates bool
 	customTemplateExt  string
+	log                *slog.Logger
 }
 
 type reader struct {
@@ -66,7 +77,8 @@ func (r *reader) Read() (*gabs.Container, error) {
 	if r.Bytes != nil {
 		return gabs.ParseJSON(r.Bytes)
 	} else if r.Map != nil {
-		return gabs.Wrap(r.Map), nil
+		// a copy, the form must not touch the caller's map
+		return gabs.Wrap(form.CopyJSON(r.Map)), nil
 	} else if r.File != "" {
 		return gabs.ParseJSONFile(r.File)
 	}
@@ -77,60 +89,40 @@ func NewBuilder() *builder {
 	return &builder{}
 }
 
+// Build compiles the form and renders it once. Use Compile to render the
+// same form many times.
 func (b *builder) Build(withIndex bool) (string, error) {
-	var html string
-
-	// schema is necessary
-	schema, err := b.schema.Read()
+	f, err := b.Compile()
 	if err != nil {
-		return html, err
+		return "", err
 	}
+	return f.Build(withIndex, RenderOptions{})
+}
 
-	// uischema is optional - generate default if not provided
-	uiSchema, err := b.uiSchema.Read()
+// Render compiles the form and writes it once as full page to w.
+func (b *builder) Render(ctx context.Context, w io.Writer) error {
+	f, err := b.Compile()
 	if err != nil {
-		return html, err
-	}
-
-	// if no uiSchema provided, generate default from schema
-	if uiSchema == nil {
-		uiSchema, err = generateDefaultUISchema(schema)
-		if err != nil {
-			return html, err
-		}
+		return err
 	}
+	return f.Render(ctx, w, RenderOptions{})
+}
 
-	var f *form.Form
-	if b.useCustomTemplates {
-		f, err = form.NewFormWithCustomTemplates(schema, uiSchema, b.customTemplateFS, b.customTemplateDir, b.useCustomTemplates)
-	} else {
-		f, err = form.NewForm(schema, uiSchema)
-	}
+// RenderFragment compiles the form and writes it once without the
+// surrounding page to w.
+func (b *builder) RenderFragment(ctx context.Context, w io.Writer) error {
+	f, err := b.Compile()
 	if err != nil {
-		return html, err
-	}
-
-	if data, err := b.data.Read(); err != nil {
-		return html, err
-	} else if data != nil {
-		f.BindData(data)
+		return err
 	}
-
-	f.SetMenu(b.menu)
-	f.SetCSS(b.cssPath)
-	f.SetLogo(b.logoPath)
-	f.SetPostLink(b.postLink)
-	f.SetConfirmation(b.confirmation)
-	f.SetCustomTemplateExt(b.customTemplateExt)
-
-	if withIndex {
-		return f.BuildIndex()
-	}
-	return f.BuildContent()
+	return f.RenderFragment(ctx, w, RenderOptions{})
 }
 
-func Verify(urlForm url.Values) interface{} {
-	return form.ReadForm(urlForm).Data()
+// Verify reads the submitted form values into data. Errors are *FormError
+// values of StageVerify.
+func Verify(urlForm url.Values) (interface{}, error) {
+	data, err := form.ReadForm(urlForm)
+	return data.Data(), err
 }
 
 func (b *builder) WithUISchemaBytes(uiSchema []byte) *builder {
@@ -188,6 +180,15 @@ func (b *builder) WithCss(cssPath string) *builder {
 	return b
 }
 
+// WithAssets links the page to the embedded htmx, script and stylesheet,
+// served by AssetHandler at assetsPath below the base path, instead of loading
+// htmx and Spectre from unpkg. The embedded stylesheet replaces Spectre, see
+// AssetHandler.
+func (b *builder) WithAssets(assetsPath string) *builder {
+	b.assetsPath = assetsPath
+	return b
+}
+
 func (b *builder) WithLogo(logoPath string) *builder {
 	b.logoPath = logoPath
 	return b
@@ -198,26 +199,86 @@ func (b *builder) WithPostLink(link string) *builder {
 	return b
 }
 
+// WithBasePath prefixes every URL of the page, i.e. menu links, post link,
+// CSS and logo, for forms served below the site root, e.g. "/admin/settings".
+// See also RenderOptions.BasePath and Mount.
+func (b *builder) WithBasePath(basePath string) *builder {
+	b.basePath = basePath
+	return b
+}
+
 func (b *builder) WithConfirmation(c models.Confirmation) *builder {
 	b.confirmation = c
 	return b
 }
 
-func (b *builder) WithCustomTemplateFS(templateDir string, templateFS embed.FS) *builder {
+// WithMaxRequestBytes limits the size of the bodies read by VerifyRequest and
+// the Handler, DefaultMaxRequestBytes by default.
+func (b *builder) WithMaxRequestBytes(n int64) *builder {
+	b.maxRequestBytes = n
+	return b
+}
+
+// WithActions replaces the submit button by a button per action, e.g. to
+// save a draft, submit or delete. Without actions, the form has a single
+// button with the confirmation of the builder.
+func (b *builder) WithActions(actions ...models.Action) *builder {
+	b.actions = actions
+	return b
+}
+
+// WithCustomTemplateFS renders the form with the templates in templateDir of
+// templateFS instead of the embedded ones. They are parsed once and cached
+// per templateDir and templateFS, see WithTemplateCacheKey and ReloadTemplates.
+// File systems that are neither comparable nor maps need a cache key.
+func (b *builder) WithCustomTemplateFS(templateDir string, templateFS fs.FS) *builder {
 	b.customTemplateDir = templateDir
 	b.customTemplateFS = templateFS
 	b.useCustomTemplates = true
 	return b
 }
 
+// WithTemplateCacheKey sets the key the custom templates are cached under,
+// e.g. to share them between forms built from different file systems. Only
+// the last 64 template sets are kept.
+func (b *builder) WithTemplateCacheKey(key string) *builder {
+	b.templateCacheKey = key
+	return b
+}
+
+// ReloadTemplates drops the cached custom templates of key, so they are parsed
+// again on the next render, e.g. after editing them during development. An
+// empty key drops all cached templates.
+func ReloadTemplates(key string) {
+	form.ReloadTemplates(key)
+}
+
 func (b *builder) WithCustomTemplateExt(ext string) *builder {
 	b.customTemplateExt = ext
 	return b
 }
 
-// generateDefaultUISchema creates a default UI schema from a JSON schema
+// WithLogger sets the logger for debug events while building the form.
+func (b *builder) WithLogger(logger *slog.Logger) *builder {
+	b.log = logger
+	return b
+}
+
+// generateDefaultUISchema creates a default UI schema from a JSON schema,
+// with the referenced schemas of its local $refs
 func generateDefaultUISchema(schema *gabs.Container) (*gabs.Container, error) {
-	return generateUISchemaFromProperties(schema, "")
+	return generateUISchemaFromProperties(form.ResolveRefs(schema), "")
+}
+
+// sortedKeys returns the keys of an object in order, so the generated UI
+// schema is the same every time.
+func sortedKeys(c *gabs.Container) []string {
+	keys := make([]string, 0, len(c.ChildrenMap()))
+	for key := range c.ChildrenMap() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 // generateUISchemaFromProperties recursively generates UI schema from properties
@@ -233,16 +294,16 @@ func generateUISchemaFromProperties(schema *gabs.Container, basePath string) (*g
 	// Get properties from schema
 	properties := schema.Path("properties")
 	if properties != nil {
-		for propertyName := range properties.ChildrenMap() {
+		for _, propertyName := range sortedKeys(properties) {
 			// Get the property schema
 			propertySchema := properties.Path(propertyName)
 
 			// Build the scope path
 			var scope string
 			if basePath == "" {
-				scope = "#/properties/" + propertyName
+				scope = "#/properties/" + form.EscapeToken(propertyName)
 			} else {
-				scope = basePath + "/properties/" + propertyName
+				scope = basePath + "/properties/" + form.EscapeToken(propertyName)
 			}
 
 			// Get the type of the property
@@ -267,9 +328,9 @@ func generateUISchemaFromProperties(schema *gabs.Container, basePath string) (*g
 
 					// Recursively generate UI schema for nested properties
 					nestedElements := make([]interface{}, 0)
-					for nestedPropertyName := range nestedProperties.ChildrenMap() {
+					for _, nestedPropertyName := range sortedKeys(nestedProperties) {
 						nestedPropertySchema := nestedProperties.Path(nestedPropertyName)
-						nestedScope := scope + "/properties/" + nestedPropertyName
+						nestedScope := scope + "/properties/" + form.EscapeToken(nestedPropertyName)
 
 						nestedControl := map[string]interface{}{
 							"type":  "Control",
@@ -310,11 +371,11 @@ func generateUISchemaFromProperties(schema *gabs.Container, basePath string) (*g
 						if itemsProperties != nil {
 							detailElements := make([]interface{}, 0)
 
-							for itemPropertyName := range itemsProperties.ChildrenMap() {
+							for _, itemPropertyName := range sortedKeys(itemsProperties) {
 								itemPropertySchema := itemsProperties.Path(itemPropertyName)
 								itemControl := map[string]interface{}{
 									"type":  "Control",
-									"scope": scope + "/items/properties/" + itemPropertyName,
+									"scope": scope + "/items/properties/" + form.EscapeToken(itemPropertyName),
 								}
 
 								// Add title from schema if available
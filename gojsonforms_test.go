@@ -0,0 +1,320 @@
+package gojsonforms_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	_, err := gojsonforms.NewBuilder().
+		WithSchemaFile("testdata/array/schema.json").
+		WithDataFile("testdata/array/data.json").
+		WithLogger(logger).
+		Build(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, event := range []string{"default uischema generated", "scope resolved", "array expanded", "template selected", "form built"} {
+		if !strings.Contains(buf.String(), event) {
+			t.Errorf("expected event %q in:\n%s", event, buf.String())
+		}
+	}
+}
+
+func TestCompileConcurrentRender(t *testing.T) {
+	uiSchema := map[string]interface{}{
+		"type": "VerticalLayout",
+		"elements": []map[string]interface{}{
+			{"type": "Control", "scope": "#/properties/name"},
+			{
+				"type":  "Control",
+				"scope": "#/properties/comments",
+				"options": map[string]interface{}{
+					"detail": map[string]interface{}{
+						"type": "VerticalLayout",
+						"elements": []interface{}{
+							map[string]interface{}{"type": "Control", "scope": "#/properties/comments/items/properties/message"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	f, err := gojsonforms.NewBuilder().
+		WithSchemaBytes([]byte(`{
+			"properties": {
+				"name": {"type": "string"},
+				"comments": {
+					"type": "array",
+					"items": {"type": "object", "properties": {"message": {"type": "string"}}}
+				}
+			}
+		}`)).
+		WithUISchemaMap(uiSchema).
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := uiSchema["elements"].([]map[string]interface{})[0]["schema"]; ok {
+		t.Error("Compile modified the uischema map")
+	}
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("name-%d", i)
+			html, err := f.Build(false, gojsonforms.RenderOptions{
+				Data: map[string]interface{}{
+					"name":     name,
+					"comments": []interface{}{map[string]interface{}{"message": "message of " + name}},
+				},
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if !strings.Contains(html, `value="`+name+`"`) || !strings.Contains(html, `value="message of `+name+`"`) {
+				t.Errorf("%s not rendered:\n%s", name, html)
+			}
+			if strings.Count(html, "/comments/0/message") != 1 || strings.Contains(html, "/comments/1/") {
+				t.Errorf("%s: array items leaked between renders:\n%s", name, html)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTemplateCache(t *testing.T) {
+	templates := fstest.MapFS{
+		"tpl/index.html": {Data: []byte(`<html>{{ template "raw.html" . }}</html>`)},
+		"tpl/raw.html":   {Data: []byte(`v1`)},
+	}
+
+	f, err := gojsonforms.NewBuilder().
+		WithSchemaFile("testdata/basic/schema.json").
+		WithCustomTemplateFS("tpl", templates).
+		WithTemplateCacheKey("TestTemplateCache").
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	render := func() string {
+		html, err := f.Build(true, gojsonforms.RenderOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return html
+	}
+
+	if html := render(); html != "<html>v1</html>" {
+		t.Fatalf("unexpected html %q", html)
+	}
+
+	// parsed once, edits show up after reloading only
+	templates["tpl/raw.html"] = &fstest.MapFile{Data: []byte(`v2`)}
+	if html := render(); html != "<html>v1</html>" {
+		t.Errorf("templates were parsed again: %q", html)
+	}
+	gojsonforms.ReloadTemplates("TestTemplateCache")
+	if html := render(); html != "<html>v2</html>" {
+		t.Errorf("templates were not reloaded: %q", html)
+	}
+}
+
+func TestTemplateCacheSameDir(t *testing.T) {
+	compile := func(content string) *gojsonforms.Form {
+		t.Helper()
+		f, err := gojsonforms.NewBuilder().
+			WithSchemaFile("testdata/basic/schema.json").
+			WithCustomTemplateFS("same", fstest.MapFS{"same/index.html": {Data: []byte(content)}}).
+			Compile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	first, second := compile("first"), compile("second")
+
+	for f, expected := range map[*gojsonforms.Form]string{first: "first", second: "second"} {
+		if html, err := f.Build(true, gojsonforms.RenderOptions{}); err != nil || html != expected {
+			t.Errorf("expected %q, got %q, %v", expected, html, err)
+		}
+	}
+
+	// the directory reloads the templates of every file system
+	gojsonforms.ReloadTemplates("same")
+	if html, err := second.Build(true, gojsonforms.RenderOptions{}); err != nil || html != "second" {
+		t.Errorf("expected %q after reloading, got %q, %v", "second", html, err)
+	}
+}
+
+func TestTemplateCacheBound(t *testing.T) {
+	first := fstest.MapFS{"bound/index.html": {Data: []byte("v1")}}
+	compile := func(fsys fs.FS) *gojsonforms.Form {
+		t.Helper()
+		f, err := gojsonforms.NewBuilder().
+			WithSchemaFile("testdata/basic/schema.json").
+			WithCustomTemplateFS("bound", fsys).
+			Compile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Build(true, gojsonforms.RenderOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	f := compile(first)
+
+	// the oldest sets are dropped once the cache is full
+	first["bound/index.html"] = &fstest.MapFile{Data: []byte("v2")}
+	for range 64 {
+		compile(fstest.MapFS{"bound/index.html": {Data: []byte("other")}})
+	}
+	if html, _ := f.Build(true, gojsonforms.RenderOptions{}); html != "v2" {
+		t.Errorf("expected the evicted templates to be parsed again, got %q", html)
+	}
+
+	// file systems without identity need a key
+	type structFS struct{ fstest.MapFS }
+	_, err := gojsonforms.NewBuilder().
+		WithSchemaFile("testdata/basic/schema.json").
+		WithCustomTemplateFS("bound", structFS{first}).
+		Compile()
+	if !errors.Is(err, gojsonforms.ErrTemplate) {
+		t.Errorf("expected ErrTemplate without cache key, got %v", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	form, err := gojsonforms.NewBuilder().
+		WithSchemaFile("testdata/array/schema.json").
+		WithUISchemaFile("testdata/array/uischema.json").
+		WithDataFile("testdata/array/data.json").
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, index := range []bool{true, false} {
+		expected, err := form.Build(index, gojsonforms.RenderOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		render := form.RenderFragment
+		if index {
+			render = form.Render
+		}
+		if err := render(context.Background(), &buf, gojsonforms.RenderOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != expected {
+			t.Errorf("index %v: rendered output differs from Build", index)
+		}
+	}
+
+	// nothing is written for canceled requests
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var buf bytes.Buffer
+	err = form.Render(ctx, &buf, gojsonforms.RenderOptions{})
+	if !errors.Is(err, context.Canceled) || buf.Len() != 0 {
+		t.Errorf("expected context.Canceled and no output, got %v and %d bytes", err, buf.Len())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	form, err := gojsonforms.NewBuilder().
+		WithSchemaBytes([]byte(`{"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}, "age": {"type": "integer"}}}`)).
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := form.Validate([]byte(`{"name": "John", "age": 42}`)); err != nil {
+		t.Errorf("expected valid data, got %v", err)
+	}
+	err = form.Validate(map[string]interface{}{"age": "42"})
+	fields := gojsonforms.FieldErrors(err)
+	if !errors.Is(err, gojsonforms.ErrValidation) || len(fields["/name"]) != 1 || len(fields["/age"]) != 1 {
+		t.Errorf("expected errors of name and age, got %v", err)
+	}
+	if err := form.Validate([]byte(`{`)); !errors.Is(err, gojsonforms.ErrInvalidData) {
+		t.Errorf("expected ErrInvalidData, got %v", err)
+	}
+
+	// the default UI schema is ordered by property
+	expected := `{"elements":[{"scope":"#/properties/age","type":"Control"},{"scope":"#/properties/name","type":"Control"}],"type":"VerticalLayout"}`
+	if uiSchema := string(form.UISchema()); uiSchema != expected {
+		t.Errorf("expected %s, got %s", expected, uiSchema)
+	}
+}
+
+func TestSchemaRefs(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"properties": {"address": {"$ref": "#/$defs/address"}},
+		"$defs": {
+			"address": {
+				"type": "object",
+				"required": ["street"],
+				"properties": {"street": {"type": "string"}, "number": {"type": "integer"}}
+			}
+		}
+	}`)
+
+	for name, uischema := range map[string][]byte{
+		"default": nil,
+		"scope":   []byte(`{"type": "Control", "scope": "#/properties/address/properties/street"}`),
+	} {
+		builder := gojsonforms.NewBuilder().WithSchemaBytes(schema)
+		if uischema != nil {
+			builder.WithUISchemaBytes(uischema)
+		}
+		f, err := builder.Compile()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		html, err := f.Build(false, gojsonforms.RenderOptions{})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !strings.Contains(html, `name="/address/street"`) || !strings.Contains(html, "required") {
+			t.Errorf("%s: expected the required street of the referenced schema in:\n%s", name, html)
+		}
+	}
+
+	f, err := gojsonforms.NewBuilder().WithSchemaBytes(schema).Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := f.Verify(url.Values{"/address/number": {"7"}})
+	if fields := gojsonforms.FieldErrors(err); !reflect.DeepEqual(fields, map[string][]string{"/address/street": {"is required"}}) {
+		t.Errorf("expected the street to be required, got %v", err)
+	}
+	if address, _ := data["address"].(map[string]interface{}); address["number"] != 7 {
+		t.Errorf("expected the number typed by the referenced schema, got %v", data)
+	}
+}
@@ -0,0 +1,205 @@
+package gojsonforms
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+)
+
+// SubmitFunc is called with the verified data of a submitted form (see
+// VerifyRequest for the bodies read), the pressed action is in ctx (see
+// ActionFromContext). Errors made with Invalid show the form again with the
+// message at the value, any other error answers with 500.
+type SubmitFunc func(ctx context.Context, data map[string]interface{}) error
+
+// Handler serves a compiled form: GET renders it, POST verifies the submitted
+// values and either renders the form again with the errors or calls the
+// SubmitFunc. It works with http.ServeMux, chi and any other router:
+//
+//	http.Handle("/settings", gojsonforms.NewHandler(form).
+//		OnSubmit(save).
+//		WithRedirect("/settings/done"))
+//
+// The form posts to the post link of the builder, so mount the handler there.
+// Forms work without JavaScript as well: they post to the same link, the
+// handler redirects after a successful submit (Post/Redirect/Get) and renders
+// the page with the errors otherwise. htmx enhances them where it is loaded.
+type Handler struct {
+	form     *Form
+	onSubmit SubmitFunc
+	redirect string
+	target   string
+	swap     string
+	csrf     CSRF
+}
+
+// NewHandler creates a handler for form.
+func NewHandler(form *Form) *Handler {
+	return &Handler{form: form}
+}
+
+// OnSubmit sets the function called with the data of every valid submit.
+func (h *Handler) OnSubmit(fn SubmitFunc) *Handler {
+	h.onSubmit = fn
+	return h
+}
+
+// WithRedirect redirects to url after a successful submit, with HX-Redirect
+// for htmx requests and 303 See Other otherwise. Without one, submits without
+// htmx are redirected back to the form.
+func (h *Handler) WithRedirect(url string) *Handler {
+	h.redirect = url
+	return h
+}
+
+// WithSwap answers successful htmx submits with the form bound to the
+// submitted data, swapped into target (a CSS selector) with the htmx swap
+// style swap, e.g. WithSwap("#form", "outerHTML").
+func (h *Handler) WithSwap(target, swap string) *Handler {
+	h.target = target
+	h.swap = swap
+	return h
+}
+
+// WithCSRF protects the form against cross-site request forgery: every page
+// carries a token of c, submits without valid token are answered with 403.
+func (h *Handler) WithCSRF(c CSRF) *Handler {
+	h.csrf = c
+	return h
+}
+
+// Invalid reports a submitted value as invalid from a SubmitFunc, e.g. a
+// name that is already taken. pointer is the data pointer of the value,
+// like "/name".
+func Invalid(pointer, message string) error {
+	return &FormError{Stage: StageVerify, Scope: pointer, Err: &ValidationError{Message: message}}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.csrf != nil {
+		token, err := h.csrf.Token(w, r)
+		if err != nil {
+			h.form.logger.ErrorContext(r.Context(), "csrf token failed", "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		r = r.WithContext(contextWithCSRFToken(r.Context(), token))
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		entry := form.EntryIndex
+		if isHtmx(r) {
+			entry = form.EntryContent
+		}
+		h.render(w, r, http.StatusOK, entry, RenderOptions{})
+	case http.MethodPost:
+		h.submit(w, r)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) submit(w http.ResponseWriter, r *http.Request) {
+	values, decoded, err := h.form.read(r)
+	if decoded == nil {
+		h.form.logger.DebugContext(r.Context(), "form rejected", "error", err)
+		if errors.Is(err, ErrTooLarge) {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if h.csrf != nil {
+		if err := h.csrf.Verify(r, submittedCSRFToken(r, values)); err != nil {
+			h.form.logger.DebugContext(r.Context(), "form rejected", "error", err)
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+	}
+
+	data, err := h.form.validate(values, decoded, err)
+	if err == nil && h.onSubmit != nil {
+		action, _ := h.form.Action(values)
+		err = h.onSubmit(contextWithAction(r.Context(), action), data)
+	}
+
+	// malformed submits, like unparsable field names, are rejected even if
+	// they come with validation errors
+	switch {
+	case err == nil:
+		h.form.logger.DebugContext(r.Context(), "form submitted")
+		h.success(w, r, data)
+	case errors.Is(err, ErrInvalidData), errors.Is(err, ErrInvalidScope):
+		h.form.logger.DebugContext(r.Context(), "form rejected", "error", err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+	case errors.Is(err, ErrValidation):
+		h.form.logger.DebugContext(r.Context(), "form invalid", "error", err)
+		h.invalid(w, r, data, err)
+	default:
+		h.form.logger.ErrorContext(r.Context(), "form submit failed", "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// invalid renders the form again with the submitted data and its errors.
+// htmx doesn't swap error responses by default, so htmx requests get 200
+// with the form swapped in place of the submitted one.
+func (h *Handler) invalid(w http.ResponseWriter, r *http.Request, data map[string]interface{}, err error) {
+	opts := RenderOptions{Data: data, Errors: err}
+	if isHtmx(r) {
+		w.Header().Set("HX-Retarget", "#form")
+		w.Header().Set("HX-Reswap", "outerHTML")
+		h.render(w, r, http.StatusOK, form.EntryFragment, opts)
+		return
+	}
+	h.render(w, r, http.StatusUnprocessableEntity, form.EntryIndex, opts)
+}
+
+func (h *Handler) success(w http.ResponseWriter, r *http.Request, data map[string]interface{}) {
+	switch {
+	case h.redirect != "" && isHtmx(r):
+		w.Header().Set("HX-Redirect", h.redirect)
+		w.WriteHeader(http.StatusOK)
+	case h.redirect != "":
+		http.Redirect(w, r, h.redirect, http.StatusSeeOther)
+	case h.target != "" && isHtmx(r):
+		w.Header().Set("HX-Retarget", h.target)
+		if h.swap != "" {
+			w.Header().Set("HX-Reswap", h.swap)
+		}
+		h.render(w, r, http.StatusOK, form.EntryFragment, RenderOptions{Data: data})
+	case isHtmx(r):
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		// Post/Redirect/Get, so reloading doesn't submit again
+		http.Redirect(w, r, h.form.link(r.Context(), h.form.page.PostLink), http.StatusSeeOther)
+	}
+}
+
+// render answers with entry rendered into a buffer, so a failing render is
+// answered with 500 instead of a partial page.
+func (h *Handler) render(w http.ResponseWriter, r *http.Request, status int, entry string, opts RenderOptions) {
+	var page bytes.Buffer
+	if err := h.form.render(r.Context(), &page, entry, opts); err != nil {
+		h.form.logger.ErrorContext(r.Context(), "form render failed", "error", err)
+		w.Header().Del("HX-Retarget")
+		w.Header().Del("HX-Reswap")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		w.Write(page.Bytes())
+	}
+}
+
+func isHtmx(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true"
+}
@@ -0,0 +1,167 @@
+package gojsonforms_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+)
+
+func newTestHandler(t *testing.T) (*gojsonforms.Handler, *map[string]interface{}) {
+	t.Helper()
+	form, err := gojsonforms.NewBuilder().
+		WithSchemaBytes([]byte(`{
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string", "minLength": 3},
+				"age": {"type": "integer", "minimum": 0},
+				"newsletter": {"type": "boolean"}
+			}
+		}`)).
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var submitted map[string]interface{}
+	handler := gojsonforms.NewHandler(form).OnSubmit(func(ctx context.Context, data map[string]interface{}) error {
+		if data["name"] == "taken" {
+			return gojsonforms.Invalid("/name", "is already taken")
+		}
+		submitted = data
+		return nil
+	})
+	return handler, &submitted
+}
+
+func post(h http.Handler, values url.Values, htmx bool) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(values.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if htmx {
+		r.Header.Set("HX-Request", "true")
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	handler, submitted := newTestHandler(t)
+
+	t.Run("get", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `name="/name"`) {
+			t.Errorf("unexpected response %d:\n%s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		w := post(handler, url.Values{"/name": {"Jo"}, "/age": {"x"}}, false)
+		body := w.Body.String()
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected 422, got %d", w.Code)
+		}
+		for _, msg := range []string{"must be at least 3 characters", "must be an integer", `value="Jo"`} {
+			if !strings.Contains(body, msg) {
+				t.Errorf("expected %q in:\n%s", msg, body)
+			}
+		}
+	})
+
+	t.Run("invalid htmx", func(t *testing.T) {
+		w := post(handler, url.Values{"/name": {"taken"}}, true)
+		if w.Code != http.StatusOK || w.Header().Get("HX-Retarget") != "#form" {
+			t.Errorf("expected the form to be swapped, got %d %v", w.Code, w.Header())
+		}
+		if body := w.Body.String(); !strings.Contains(body, "is already taken") || strings.Contains(body, "<html") {
+			t.Errorf("expected form fragment with error, got:\n%s", body)
+		}
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		w := post(handler, url.Values{"/name": {"John"}, "/age": {"42"}}, true)
+		if w.Code != http.StatusNoContent {
+			t.Errorf("expected 204, got %d", w.Code)
+		}
+		expected := map[string]interface{}{"name": "John", "age": 42, "newsletter": false}
+		for key, value := range expected {
+			if (*submitted)[key] != value {
+				t.Errorf("expected %s = %v, got %v", key, value, (*submitted)[key])
+			}
+		}
+	})
+
+	t.Run("valid without htmx", func(t *testing.T) {
+		w := post(handler, url.Values{"/name": {"John"}}, false)
+		if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/" {
+			t.Errorf("expected 303 back to the form, got %d %v", w.Code, w.Header())
+		}
+	})
+
+	t.Run("redirect", func(t *testing.T) {
+		handler.WithRedirect("/done")
+		defer handler.WithRedirect("")
+
+		if w := post(handler, url.Values{"/name": {"John"}}, false); w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/done" {
+			t.Errorf("expected 303 to /done, got %d %v", w.Code, w.Header())
+		}
+		if w := post(handler, url.Values{"/name": {"John"}}, true); w.Header().Get("HX-Redirect") != "/done" {
+			t.Errorf("expected HX-Redirect to /done, got %v", w.Header())
+		}
+	})
+
+	t.Run("unknown fields", func(t *testing.T) {
+		w := post(handler, url.Values{"/name": {"John"}, "/isAdmin": {"true"}}, true)
+		if w.Code != http.StatusNoContent {
+			t.Errorf("expected 204, got %d", w.Code)
+		}
+		if _, ok := (*submitted)["isAdmin"]; ok {
+			t.Errorf("expected fields without schema to be dropped, got %v", *submitted)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, values := range []url.Values{
+			{"#x": {"1"}},
+			{"/name": {"Jo"}, "#x": {"1"}},
+		} {
+			if w := post(handler, values, false); w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400 for %v, got %d", values, w.Code)
+			}
+		}
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/", nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("expected 405, got %d", w.Code)
+		}
+	})
+}
+
+func TestHandlerRenderFailed(t *testing.T) {
+	form, err := gojsonforms.NewBuilder().
+		WithSchemaBytes([]byte(`{"properties": {"name": {"type": "string"}}}`)).
+		WithCustomTemplateFS("tpl", fstest.MapFS{
+			"tpl/index.html": {Data: []byte(`<html>{{ template "missing.html" . }}</html>`)},
+		}).
+		WithTemplateCacheKey("TestHandlerRenderFailed").
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	gojsonforms.NewHandler(form).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "<html>") {
+		t.Errorf("expected 500 without a partial page, got %d:\n%s", w.Code, w.Body.String())
+	}
+}
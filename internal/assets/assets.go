@@ -0,0 +1,112 @@
+// Package assets embeds the scripts and stylesheets of the form pages and the
+// playground, so pages work without a CDN, e.g. in air-gapped networks.
+//
+// The stylesheet is part of the repository and replaces Spectre, whose classes
+// the templates use, rather than copying it. htmx is vendored with
+// `make assets` at HtmxVersion. Pages linking the assets never load anything
+// from a CDN.
+package assets
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"embed"
+	"encoding/base64"
+	"encoding/hex"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// HtmxVersion is the pinned htmx release, vendored by `make assets`.
+const HtmxVersion = "2.0.2"
+
+const (
+	Htmx   = "htmx.min.js"
+	Css    = "jsonforms.css"
+	Script = "jsonforms.js"
+	// PlaygroundScript and PlaygroundCss are the assets of the playground
+	PlaygroundScript = "playground.js"
+	PlaygroundCss    = "playground.css"
+)
+
+//go:embed static
+var static embed.FS
+
+// Asset is an embedded file.
+type Asset struct {
+	Name string
+	// Integrity is the subresource integrity hash, "sha384-..."
+	Integrity string
+	// Version changes with the content and makes URLs cacheable forever
+	Version string
+	data    []byte
+}
+
+// URL is the path of the asset below the path the Handler is served at.
+func (a *Asset) URL() string {
+	return a.Name + "?v=" + a.Version
+}
+
+// Bytes returns the content of the asset, e.g. to inline it.
+func (a *Asset) Bytes() []byte {
+	return a.data
+}
+
+var assets = load()
+
+func load() map[string]*Asset {
+	assets := map[string]*Asset{}
+	entries, _ := fs.ReadDir(static, "static")
+	for _, entry := range entries {
+		data, err := fs.ReadFile(static, path.Join("static", entry.Name()))
+		if err != nil {
+			continue
+		}
+		sum := sha512.Sum384(data)
+		assets[entry.Name()] = &Asset{
+			Name:      entry.Name(),
+			Integrity: "sha384-" + base64.StdEncoding.EncodeToString(sum[:]),
+			Version:   hex.EncodeToString(sum[:6]),
+			data:      data,
+		}
+	}
+	return assets
+}
+
+// Lookup returns the embedded asset name, false if it isn't embedded.
+func Lookup(name string) (*Asset, bool) {
+	a, ok := assets[name]
+	return a, ok
+}
+
+// Handler serves the embedded assets by name. Requests for the current
+// version (see Asset.URL) may be cached forever, all others are revalidated
+// by ETag.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		a, ok := Lookup(strings.TrimPrefix(r.URL.Path, "/"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(a.Name)))
+		w.Header().Set("ETag", `"`+a.Version+`"`)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if r.URL.Query().Get("v") == a.Version {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		http.ServeContent(w, r, a.Name, time.Time{}, bytes.NewReader(a.data))
+	})
+}
@@ -0,0 +1,18 @@
+package assets_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/TobiEiss/go-jsonforms/internal/assets"
+)
+
+func TestHtmxEmbedded(t *testing.T) {
+	htmx, ok := assets.Lookup(assets.Htmx)
+	if !ok {
+		t.Fatalf("%s is not embedded, vendor it with `make assets`", assets.Htmx)
+	}
+	if !bytes.Contains(htmx.Bytes(), []byte(`"`+assets.HtmxVersion+`"`)) {
+		t.Errorf("expected htmx %s embedded", assets.HtmxVersion)
+	}
+}
@@ -0,0 +1,140 @@
+// Behaviour of the form pages. It binds no inline handlers, so pages work
+// under a Content Security Policy without 'unsafe-inline'.
+(function () {
+  "use strict";
+
+  // arraySelect fills the detail of an array-select with the selected element
+  // and names its inputs after it, so they are submitted into that element
+  function arraySelect(select) {
+    const selectedOption = select.options[select.selectedIndex];
+    if (!selectedOption) return;
+    const data = JSON.parse(selectedOption.dataset["element"]);
+    const detail = document.getElementById(select.dataset["jsonformsSelect"]);
+    if (!detail) return;
+    const array = select.dataset["jsonformsArray"];
+    for (const input of detail.querySelectorAll("[data-jsonforms-path]")) {
+      if (array) {
+        input.name = array + "/" + selectedOption.value + "/" + input.dataset["jsonformsPath"];
+      }
+      const val = getValueFromPath(data, input.dataset["jsonformsPath"]);
+      if (val === undefined) continue;
+      if (input.type === "checkbox") {
+        input.checked = val === true;
+      } else {
+        input.value = val;
+      }
+    }
+  }
+
+  // path is a relative JSON pointer like "person/name"
+  function getValueFromPath(obj, path) {
+    const keys = path.split("/").map(key => key.replaceAll("~1", "/").replaceAll("~0", "~"));
+    let current = obj;
+    for (const key of keys) {
+      if (current == null || current[key] === undefined) {
+        return undefined;
+      }
+      current = current[key];
+    }
+    return current;
+  }
+
+  // init pre-fills the details of all array-selects below root
+  function init(root) {
+    root.querySelectorAll("select[data-jsonforms-select]").forEach(arraySelect);
+  }
+
+  document.addEventListener("change", function (e) {
+    if (e.target.matches("select[data-jsonforms-select]")) {
+      arraySelect(e.target);
+    }
+  });
+
+  if (document.readyState === "loading") {
+    document.addEventListener("DOMContentLoaded", () => init(document));
+  } else {
+    init(document);
+  }
+  document.addEventListener("htmx:afterSwap", e => init(e.detail.elt));
+
+  // the button a form was submitted with, for its confirmation
+  const submitters = new WeakMap();
+  document.addEventListener("submit", e => submitters.set(e.target, e.submitter), true);
+
+  // display formats the value of a form element as text
+  function display(el) {
+    if (el.type === "checkbox") return el.checked ? "\u2713" : "\u2717";
+    if (el.tagName === "SELECT") return el.selectedIndex < 0 ? "" : el.options[el.selectedIndex].text;
+    return el.value;
+  }
+
+  // confirmation fills the placeholders and summary of a confirmation dialog
+  // with the values of form, always as text
+  function confirmation(dialog, form) {
+    for (const span of dialog.querySelectorAll("[data-jsonforms-value]")) {
+      const el = form.elements.namedItem(span.dataset["jsonformsValue"]);
+      span.textContent = el && el.tagName ? display(el) : "";
+    }
+    const summary = dialog.querySelector("[data-jsonforms-summary]");
+    if (!summary) return;
+    summary.replaceChildren();
+    for (const el of form.elements) {
+      if (!el.name || el.disabled || el.type === "hidden" || el.type === "submit") continue;
+      const dt = document.createElement("dt");
+      dt.textContent = el.labels && el.labels.length ? el.labels[0].textContent.trim() : el.name;
+      const dd = document.createElement("dd");
+      dd.textContent = display(el);
+      summary.append(dt, dd);
+    }
+  }
+
+  // the dialogs are rendered inside the form they confirm, their buttons
+  // close them instead of a nested form
+  document.addEventListener("click", function (e) {
+    const button = e.target.closest("[data-jsonforms-close]");
+    const dialog = button && button.closest("dialog");
+    if (dialog) dialog.close(button.value);
+  });
+
+  // buttons with data-jsonforms-confirm ask in the dialog of that id first,
+  // without the dialog they don't submit at all
+  document.addEventListener("htmx:confirm", function (e) {
+    // buttons with their own target issue the request themselves
+    const elt = e.detail.elt;
+    const submitter = elt.tagName === "FORM" ? submitters.get(elt) : elt;
+    if (!submitter || !submitter.dataset["jsonformsConfirm"]) return;
+    e.preventDefault();
+    const dialog = document.getElementById(submitter.dataset["jsonformsConfirm"]);
+    if (!dialog) return;
+    confirmation(dialog, elt.form || elt);
+    dialog.returnValue = "";
+    dialog.addEventListener("close", function () {
+      if (dialog.returnValue === "confirm") {
+        e.detail.issueRequest(true);
+      }
+    }, { once: true });
+    dialog.showModal();
+  });
+
+  // without htmx, the same buttons ask before the form is submitted
+  const confirmed = new WeakSet();
+  document.addEventListener("submit", function (e) {
+    if (window.htmx) return;
+    const submitter = e.submitter;
+    if (!submitter || !submitter.dataset["jsonformsConfirm"]) return;
+    if (confirmed.delete(submitter)) return;
+    e.preventDefault();
+    const dialog = document.getElementById(submitter.dataset["jsonformsConfirm"]);
+    if (!dialog) return;
+    const form = e.target;
+    confirmation(dialog, form);
+    dialog.returnValue = "";
+    dialog.addEventListener("close", function () {
+      if (dialog.returnValue === "confirm") {
+        confirmed.add(submitter);
+        form.requestSubmit(submitter);
+      }
+    }, { once: true });
+    dialog.showModal();
+  });
+})();
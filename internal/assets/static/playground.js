@@ -0,0 +1,90 @@
+/*
+ * Behaviour of the playground: the editors are previewed as you type, submits
+ * in the preview are verified by the server instead of posted.
+ */
+(function () {
+  "use strict";
+
+  var root = document.getElementById("playground");
+  var editors = {
+    schema: document.getElementById("playground-schema"),
+    uischema: document.getElementById("playground-uischema"),
+    data: document.getElementById("playground-data")
+  };
+  var preview = document.getElementById("playground-preview");
+  var error = document.getElementById("playground-error");
+  var tree = document.getElementById("playground-tree");
+  var output = document.getElementById("playground-output");
+  var timer;
+
+  function sources() {
+    return {
+      schema: editors.schema.value,
+      uischema: editors.uischema.value,
+      data: editors.data.value
+    };
+  }
+
+  function post(url, body) {
+    return fetch(url, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(body)
+    }).then(function (res) {
+      return res.json();
+    });
+  }
+
+  function show(result) {
+    error.textContent = result.error || "";
+    error.hidden = !result.error;
+    if (result.html) {
+      preview.srcdoc = result.html;
+    }
+    if (result.tree) {
+      tree.textContent = JSON.stringify(result.tree, null, 2);
+    }
+  }
+
+  function failed(err) {
+    show({ error: String(err) });
+  }
+
+  function update() {
+    post(root.dataset.preview, sources()).then(show, failed);
+  }
+
+  // submit verifies the values of a form in the preview
+  function submit(event) {
+    var form = event.target;
+    if (form.tagName !== "FORM") {
+      return;
+    }
+    event.preventDefault();
+    event.stopImmediatePropagation();
+
+    var values = {};
+    new FormData(form, event.submitter).forEach(function (value, name) {
+      (values[name] = values[name] || []).push(String(value));
+    });
+    var body = sources();
+    body.values = values;
+    post(root.dataset.submit, body).then(function (result) {
+      output.textContent = JSON.stringify({ data: result.data, errors: result.errors }, null, 2);
+      show(result);
+    }, failed);
+  }
+
+  preview.addEventListener("load", function () {
+    preview.contentDocument.addEventListener("submit", submit, true);
+  });
+
+  Object.keys(editors).forEach(function (key) {
+    editors[key].addEventListener("input", function () {
+      clearTimeout(timer);
+      timer = setTimeout(update, 300);
+    });
+  });
+
+  update();
+})();
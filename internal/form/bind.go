@@ -0,0 +1,291 @@
+package form
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	gabs "github.com/Jeffail/gabs/v2"
+)
+
+// binder binds data to a compiled tree. Every node is copied, so the compiled
+// tree is never modified.
+type binder struct {
+	data   *gabs.Container
+	logger *slog.Logger
+	errs   *[]error
+	// fieldErrors are the messages of the submitted values by data pointer
+	fieldErrors map[string][]string
+	// rewrites replace the item scopes of arrays by the index of the element
+	rewrites []rewrite
+	// suffix keeps the ids of array elements apart
+	suffix string
+	// relativeTo is the item scope of an array-select, its detail is filled
+	// in the browser with the selected element
+	relativeTo string
+	// selected is the data pointer of the element the detail of an
+	// array-select is bound to first
+	selected []string
+}
+
+type rewrite struct {
+	from, to string
+}
+
+func (b *binder) fail(err error) {
+	*b.errs = append(*b.errs, err)
+}
+
+// scope applies the rewrites of the enclosing arrays, outermost first.
+func (b *binder) scope(scope string) string {
+	for _, r := range b.rewrites {
+		if scope == r.from || strings.HasPrefix(scope, r.from+"/") {
+			scope = r.to + strings.TrimPrefix(scope, r.from)
+		}
+	}
+	return scope
+}
+
+func (b *binder) bind(node Node) Node {
+	switch n := node.(type) {
+	case *Layout:
+		layout := *n
+		b.base(&layout.Base)
+		layout.Elements = b.bindAll(n.Elements)
+		return &layout
+	case *Group:
+		group := *n
+		b.base(&group.Base)
+		group.Elements = b.bindAll(n.Elements)
+		return &group
+	case *Label:
+		label := *n
+		b.base(&label.Base)
+		return &label
+	case *Control:
+		control := *n
+		b.base(&control.Base)
+		b.control(&control)
+		return &control
+	case *ArrayControl:
+		array := *n
+		b.base(&array.Base)
+		b.array(&array)
+		return &array
+	}
+	return node
+}
+
+func (b *binder) bindAll(nodes []Node) []Node {
+	bound := make([]Node, len(nodes))
+	for i, node := range nodes {
+		bound[i] = b.bind(node)
+	}
+	return bound
+}
+
+// base applies the id suffix and the rule of the element.
+func (b *binder) base(base *Base) {
+	base.ID += b.suffix
+	if base.Rule == nil {
+		return
+	}
+
+	rule := *base.Rule
+	rule.Scope = b.scope(rule.Scope)
+	base.Rule = &rule
+
+	tokens, err := DataTokens(rule.Scope)
+	if err != nil {
+		b.fail(&FormError{Stage: StageBind, Scope: rule.Scope, Err: err})
+		return
+	}
+	match := rule.matches(b.data.Search(tokens...).Data())
+	switch rule.Effect {
+	case "HIDE":
+		base.Visible = !match
+	case "SHOW":
+		base.Visible = match
+	case "DISABLE":
+		base.Enabled = !match
+	case "ENABLE":
+		base.Enabled = match
+	}
+}
+
+func (b *binder) control(c *Control) {
+	c.Scope = b.scope(c.Scope)
+	if c.Schema.ReadOnly {
+		c.Enabled = false
+	}
+	if readonly, _ := c.Options["readonly"].(bool); readonly {
+		c.Enabled = false
+	}
+
+	// details of array-selects are relative to the selected element
+	if b.relativeTo != "" {
+		if c.Scope != b.relativeTo && !strings.HasPrefix(c.Scope, b.relativeTo+"/") {
+			b.fail(newError(StageBind, c.Scope, ErrInvalidScope, "no item scope of %s", b.relativeTo))
+			return
+		}
+		tokens, err := DataTokens("#" + strings.TrimPrefix(c.Scope, b.relativeTo))
+		if err != nil {
+			b.fail(&FormError{Stage: StageBind, Scope: c.Scope, Err: err})
+			return
+		}
+		// named after the selected element, the script renames them
+		// when another one is selected
+		c.Path = relativePointer(tokens)
+		tokens = append(b.selected[:len(b.selected):len(b.selected)], tokens...)
+		c.Name = Pointer(tokens)
+		c.Value = b.data.Search(tokens...).Data()
+		c.Errors = b.fieldErrors[c.Name]
+		return
+	}
+
+	tokens, err := DataTokens(c.Scope)
+	if err != nil {
+		b.fail(&FormError{Stage: StageBind, Scope: c.Scope, Err: err})
+		return
+	}
+	c.Name = Pointer(tokens)
+	c.Value = b.data.Search(tokens...).Data()
+	c.Errors = b.fieldErrors[c.Name]
+}
+
+func (b *binder) array(a *ArrayControl) {
+	b.control(&a.Control)
+	if b.relativeTo != "" {
+		return
+	}
+
+	tokens, err := DataTokens(a.Scope)
+	if err != nil {
+		return
+	}
+	elements := b.data.Search(tokens...)
+
+	if a.Select {
+		if elements != nil {
+			choices, err := selectOptions(a.Options, elements)
+			if err != nil {
+				b.fail(newError(StageBind, a.Scope, ErrInvalidData, "%v", err))
+			}
+			a.Choices = choices
+		}
+		selected := "0"
+		if len(a.Choices) > 0 {
+			selected = a.Choices[0].Key
+		}
+		child := *b
+		child.relativeTo = a.Scope + "/items"
+		child.selected = append(tokens[:len(tokens):len(tokens)], selected)
+		a.Detail = child.bind(a.Detail)
+		return
+	}
+
+	// one detail per element
+	count := 0
+	if elements != nil {
+		if count, err = elements.ArrayCount(); err != nil {
+			b.fail(newError(StageBind, a.Scope, ErrInvalidData, "%v", err))
+			return
+		}
+	}
+	b.logger.Debug("array expanded", "scope", a.Scope, "items", count)
+
+	a.Items = make([]Node, count)
+	for i := range count {
+		child := *b
+		child.rewrites = append(append([]rewrite{}, b.rewrites...), rewrite{
+			from: a.Scope + "/items",
+			to:   a.Scope + "/" + strconv.Itoa(i),
+		})
+		child.suffix = b.suffix + "-" + strconv.Itoa(i)
+		a.Items[i] = child.bind(a.Detail)
+	}
+}
+
+// selectOptions builds the choices of an array-select control. Every choice
+// carries the index of its element as key, so duplicate labels stay apart.
+// The label is built from options.elementLabelProps (or elementLabelProp) and
+// can be shaped with options.labelFormat, e.g. "%s, %s".
+func selectOptions(options map[string]interface{}, elements *gabs.Container) ([]Choice, error) {
+	var labelProps []string
+	if prop, ok := options["elementLabelProp"].(string); ok {
+		labelProps = append(labelProps, prop)
+	}
+	if props, ok := options["elementLabelProps"].([]interface{}); ok {
+		for _, prop := range props {
+			p, ok := prop.(string)
+			if !ok {
+				return nil, fmt.Errorf("elementLabelProps: %v is no string", prop)
+			}
+			labelProps = append(labelProps, p)
+		}
+	}
+	labelFormat, _ := options["labelFormat"].(string)
+
+	choices := []Choice{}
+	for i, element := range elements.Children() {
+		// elements not submitted with the selected one
+		if element.Data() == nil {
+			continue
+		}
+		values := make([]string, 0, len(labelProps))
+		for _, prop := range labelProps {
+			tokens := labelTokens(prop)
+			if !element.Exists(tokens...) {
+				return choices, fmt.Errorf("element %d has no label prop %q", i, prop)
+			}
+			values = append(values, labelValue(element.Search(tokens...).Data()))
+		}
+
+		var label string
+		switch {
+		case labelFormat != "":
+			args := make([]interface{}, len(values))
+			for j, v := range values {
+				args[j] = v
+			}
+			label = fmt.Sprintf(labelFormat, args...)
+		case len(values) > 0:
+			label = strings.Join(values, " ")
+		default:
+			label = strconv.Itoa(i)
+		}
+
+		choices = append(choices, Choice{
+			Key:     strconv.Itoa(i),
+			Label:   label,
+			Element: element.Data(),
+		})
+	}
+	return choices, nil
+}
+
+// labelTokens splits a label prop, given either as JSON pointer ("/person/name")
+// or in dot notation ("person.name").
+func labelTokens(prop string) []string {
+	if strings.HasPrefix(prop, "/") {
+		if tokens, err := gabs.JSONPointerToSlice(prop); err == nil {
+			return tokens
+		}
+	}
+	return gabs.DotPathToSlice(prop)
+}
+
+// labelValue formats a JSON value of any type as label text.
+func labelValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
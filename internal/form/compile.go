@@ -0,0 +1,206 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	gabs "github.com/Jeffail/gabs/v2"
+)
+
+// compile resolves a UI schema element and everything below into a node.
+func (f *Form) compile(c *gabs.Container, pointer string, col int) (Node, error) {
+	elementType, _ := c.Search("type").Data().(string)
+	options, _ := c.Search("options").Data().(map[string]interface{})
+	base := Base{
+		ID:      nodeID(pointer),
+		Pointer: pointer,
+		Type:    elementType,
+		Col:     col,
+		Options: options,
+		Rule:    compileRule(c),
+		Visible: true,
+		Enabled: true,
+	}
+
+	switch elementType {
+	case "VerticalLayout", "HorizontalLayout":
+		base.NodeKind = "Layout"
+		elements, err := f.compileElements(c, pointer, elementType)
+		return &Layout{Base: base, Elements: elements}, err
+	case "Group":
+		base.NodeKind = "Group"
+		label, _ := c.Search("label").Data().(string)
+		elements, err := f.compileElements(c, pointer, elementType)
+		return &Group{Base: base, Label: label, Elements: elements}, err
+	case "Label":
+		base.NodeKind = "Label"
+		text, _ := c.Search("text").Data().(string)
+		return &Label{Base: base, Text: text}, nil
+	case "Control":
+		return f.compileControl(c, base)
+	}
+	return nil, newError(StageSetup, "", ErrUnknownElementType, "%q at %s", elementType, pointer)
+}
+
+// compileElements compiles the elements of a layout and sizes them in the grid.
+func (f *Form) compileElements(c *gabs.Container, pointer, layoutType string) ([]Node, error) {
+	var errs []error
+	children := c.Search("elements").Children()
+
+	col := 0
+	switch {
+	case layoutType == "VerticalLayout":
+		col = 12
+	case layoutType == "HorizontalLayout" && len(children) > 0:
+		col = 12 / len(children)
+	}
+
+	elements := make([]Node, 0, len(children))
+	for i, child := range children {
+		node, err := f.compile(child, pointer+"/elements/"+strconv.Itoa(i), col)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if node != nil {
+			elements = append(elements, node)
+		}
+	}
+	return elements, errors.Join(errs...)
+}
+
+func (f *Form) compileControl(c *gabs.Container, base Base) (Node, error) {
+	scope, ok := c.Search("scope").Data().(string)
+	if !ok {
+		return nil, newError(StageSetup, "", ErrMissingScope, "at %s", base.Pointer)
+	}
+
+	tokens, err := ScopeTokens(scope)
+	if err != nil {
+		return nil, &FormError{Stage: StageSetup, Scope: scope, Err: err}
+	}
+
+	raw, ok := f.schema.Search(tokens...).Data().(map[string]interface{})
+	if !ok {
+		return nil, newError(StageSetup, scope, ErrUnresolvedScope, "")
+	}
+	schema := newSchema(raw)
+	f.logger.Debug("scope resolved", "scope", scope, "type", schema.Type)
+
+	base.NodeKind = "Control"
+	control := Control{
+		Base:     base,
+		Scope:    scope,
+		Label:    schema.Title,
+		Schema:   schema,
+		Required: f.required(tokens),
+	}
+	switch label := c.Search("label").Data().(type) {
+	case string:
+		control.Label = label
+	case bool:
+		if !label {
+			control.Label = ""
+		}
+	}
+
+	if schema.Type != "array" && schema.Type != "array-select" {
+		return &control, nil
+	}
+
+	control.NodeKind = "ArrayControl"
+	detail, err := f.compileDetail(c, scope, raw, base.Pointer+"/options/detail")
+	return &ArrayControl{Control: control, Detail: detail, Select: schema.Type == "array-select"}, err
+}
+
+// compileDetail compiles the layout of a single array element. Without
+// options.detail (or with "GENERATED") it is generated from the items schema.
+func (f *Form) compileDetail(c *gabs.Container, scope string, raw map[string]interface{}, pointer string) (Node, error) {
+	if detail := c.Search("options", "detail"); detail != nil {
+		if _, ok := detail.Data().(map[string]interface{}); ok {
+			return f.compile(detail, pointer, 0)
+		}
+	}
+
+	itemScope := scope + "/items"
+	items, _ := raw["items"].(map[string]interface{})
+	properties, _ := items["properties"].(map[string]interface{})
+	if len(properties) == 0 {
+		return f.compile(gabs.Wrap(map[string]interface{}{
+			"type":  "Control",
+			"scope": itemScope,
+		}), pointer, 0)
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	elements := make([]interface{}, len(names))
+	for i, name := range names {
+		elements[i] = map[string]interface{}{
+			"type":  "Control",
+			"scope": itemScope + "/properties/" + EscapeToken(name),
+		}
+	}
+	return f.compile(gabs.Wrap(map[string]interface{}{
+		"type":     "VerticalLayout",
+		"elements": elements,
+	}), pointer, 0)
+}
+
+// required reports whether the parent object requires the property at tokens.
+func (f *Form) required(tokens []string) bool {
+	n := len(tokens)
+	if n < 2 || tokens[n-2] != "properties" {
+		return false
+	}
+	for _, name := range f.schema.Search(tokens[:n-2]...).Search("required").Children() {
+		if name.Data() == tokens[n-1] {
+			return true
+		}
+	}
+	return false
+}
+
+// compileRule reads the rule of an element, if it has a supported one.
+func compileRule(c *gabs.Container) *Rule {
+	effect, ok := c.Search("rule", "effect").Data().(string)
+	if !ok {
+		return nil
+	}
+	scope, ok := c.Search("rule", "condition", "scope").Data().(string)
+	if !ok {
+		return nil
+	}
+
+	rule := &Rule{
+		Effect:        effect,
+		Scope:         scope,
+		ExpectedValue: c.Search("rule", "condition", "expectedValue").Data(),
+	}
+	rule.Schema, _ = c.Search("rule", "condition", "schema").Data().(map[string]interface{})
+	return rule
+}
+
+// matches checks value against the const and enum of a rule condition.
+func (r *Rule) matches(value interface{}) bool {
+	if r.Schema == nil {
+		return fmt.Sprint(value) == fmt.Sprint(r.ExpectedValue)
+	}
+	if expected, ok := r.Schema["const"]; ok && fmt.Sprint(value) != fmt.Sprint(expected) {
+		return false
+	}
+	if enum, ok := r.Schema["enum"].([]interface{}); ok {
+		for _, expected := range enum {
+			if fmt.Sprint(value) == fmt.Sprint(expected) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
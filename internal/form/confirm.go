@@ -0,0 +1,43 @@
+package form
+
+import (
+	"regexp"
+
+	"github.com/TobiEiss/go-jsonforms/models"
+)
+
+// confirmID is the id of the confirmation dialog of an action.
+func confirmID(action models.Action) string {
+	if action.Name == "" {
+		return "jsonforms-confirm"
+	}
+	return "jsonforms-confirm-" + action.Name
+}
+
+// placeholder matches the data pointers in the body of a confirmation, like
+// "Save {/name}?".
+var placeholder = regexp.MustCompile(`\{(/[^{}]*)\}`)
+
+// BodyPart is either text or the data pointer of a value filled in the
+// browser when the confirmation is shown.
+type BodyPart struct {
+	Text    string
+	Pointer string
+}
+
+// placeholders splits the body of a confirmation into text and placeholders.
+func placeholders(body string) []BodyPart {
+	var parts []BodyPart
+	last := 0
+	for _, m := range placeholder.FindAllStringSubmatchIndex(body, -1) {
+		if m[0] > last {
+			parts = append(parts, BodyPart{Text: body[last:m[0]]})
+		}
+		parts = append(parts, BodyPart{Pointer: body[m[2]:m[3]]})
+		last = m[1]
+	}
+	if last < len(body) {
+		parts = append(parts, BodyPart{Text: body[last:]})
+	}
+	return parts
+}
@@ -0,0 +1,24 @@
+package form
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		body     string
+		expected []BodyPart
+	}{
+		{"", nil},
+		{"Sure?", []BodyPart{{Text: "Sure?"}}},
+		{"Save {/name}?", []BodyPart{{Text: "Save "}, {Pointer: "/name"}, {Text: "?"}}},
+		{"{/first}{/last}", []BodyPart{{Pointer: "/first"}, {Pointer: "/last"}}},
+		{"{name} ${/x} {}", []BodyPart{{Text: "{name} $"}, {Pointer: "/x"}, {Text: " {}"}}},
+	}
+	for _, test := range tests {
+		if parts := placeholders(test.body); !reflect.DeepEqual(parts, test.expected) {
+			t.Errorf("placeholders(%q) = %+v, expected %+v", test.body, parts, test.expected)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoSchema is returned if a form is built without schema.
+	ErrNoSchema = errors.New("no schema")
+	// ErrMissingScope is returned if a Control has no scope.
+	ErrMissingScope = errors.New("missing scope")
+	// ErrInvalidScope is returned if a scope is no valid JSON pointer.
+	ErrInvalidScope = errors.New("invalid scope")
+	// ErrUnresolvedScope is returned if a scope does not resolve in the schema.
+	ErrUnresolvedScope = errors.New("unresolved scope")
+	// ErrUnresolvedRef is returned for a $ref that does not resolve in the schema.
+	ErrUnresolvedRef = errors.New("unresolved $ref")
+	// ErrUnknownElementType is returned for UI schema elements of unknown type.
+	ErrUnknownElementType = errors.New("unknown element type")
+	// ErrInvalidUISchema is returned if the UI schema is malformed.
+	ErrInvalidUISchema = errors.New("invalid uischema")
+	// ErrInvalidData is returned if data doesn't fit the form.
+	ErrInvalidData = errors.New("invalid data")
+	// ErrValidation is returned for submitted values that don't match the schema.
+	ErrValidation = errors.New("invalid value")
+	// ErrTemplate is returned if a template can't be parsed or executed.
+	ErrTemplate = errors.New("template error")
+	// ErrTooLarge is returned for submitted bodies over the size limit.
+	ErrTooLarge = errors.New("request too large")
+)
+
+// Stage names the step of the form pipeline an error occurred in.
+type Stage string
+
+const (
+	StageRead     Stage = "read"
+	StageValidate Stage = "validate"
+	StageSetup    Stage = "setup"
+	StageBind     Stage = "bind"
+	StageRender   Stage = "render"
+	StageVerify   Stage = "verify"
+)
+
+// FormError is the error reported by every stage of the form pipeline.
+// Use errors.Is with the sentinel errors above to check the cause.
+type FormError struct {
+	Stage Stage
+	// Scope is the UI schema scope or data pointer the error belongs to, if any
+	Scope string
+	Err   error
+}
+
+func (e *FormError) Error() string {
+	if e.Scope != "" {
+		return fmt.Sprintf("jsonforms: %s %s: %v", e.Stage, e.Scope, e.Err)
+	}
+	return fmt.Sprintf("jsonforms: %s: %v", e.Stage, e.Err)
+}
+
+func (e *FormError) Unwrap() error {
+	return e.Err
+}
+
+// newError wraps a sentinel error with details into a *FormError.
+func newError(stage Stage, scope string, sentinel error, format string, args ...interface{}) *FormError {
+	err := sentinel
+	if format != "" {
+		err = fmt.Errorf("%w: %s", sentinel, fmt.Sprintf(format, args...))
+	}
+	return &FormError{Stage: stage, Scope: scope, Err: err}
+}
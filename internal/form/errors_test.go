@@ -0,0 +1,118 @@
+package form_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	gabs "github.com/Jeffail/gabs/v2"
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+)
+
+func TestFormErrors(t *testing.T) {
+	schema := `{
+		"properties": {
+			"name": {"type": "string"},
+			"comments": {
+				"type": "array",
+				"items": {"type": "object", "properties": {"message": {"type": "string"}}}
+			},
+			"people": {
+				"type": "array-select",
+				"items": {"type": "object", "properties": {"name": {"type": "string"}}}
+			}
+		}
+	}`
+
+	tests := []struct {
+		testStep string
+		uiSchema string
+		data     string
+		stage    form.Stage
+		scope    string
+		sentinel error
+	}{
+		{
+			testStep: "missing scope",
+			uiSchema: `{"type": "VerticalLayout", "elements": [{"type": "Control"}]}`,
+			stage:    form.StageSetup,
+			sentinel: form.ErrMissingScope,
+		},
+		{
+			testStep: "unknown element type",
+			uiSchema: `{"type": "VerticalLayout", "elements": [{"type": "Table"}]}`,
+			stage:    form.StageSetup,
+			sentinel: form.ErrUnknownElementType,
+		},
+		{
+			testStep: "unresolved scope",
+			uiSchema: `{"type": "VerticalLayout", "elements": [{"type": "Control", "scope": "#/properties/unknown"}]}`,
+			stage:    form.StageSetup,
+			scope:    "#/properties/unknown",
+			sentinel: form.ErrUnresolvedScope,
+		},
+		{
+			testStep: "array without array data",
+			uiSchema: `{"type": "VerticalLayout", "elements": [{
+				"type": "Control",
+				"scope": "#/properties/comments",
+				"options": {"detail": {"type": "VerticalLayout", "elements": []}}
+			}]}`,
+			data:     `{"comments": "no array"}`,
+			stage:    form.StageBind,
+			scope:    "#/properties/comments",
+			sentinel: form.ErrInvalidData,
+		},
+		{
+			testStep: "missing label prop",
+			uiSchema: `{"type": "VerticalLayout", "elements": [{
+				"type": "Control",
+				"scope": "#/properties/people",
+				"options": {"elementLabelProp": "name", "detail": {"type": "VerticalLayout", "elements": []}}
+			}]}`,
+			data:     `{"people": [{"id": 1}]}`,
+			stage:    form.StageBind,
+			scope:    "#/properties/people",
+			sentinel: form.ErrInvalidData,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testStep, func(t *testing.T) {
+			schema, _ := gabs.ParseJSON([]byte(schema))
+			uiSchema, _ := gabs.ParseJSON([]byte(test.uiSchema))
+
+			f, err := form.NewForm(schema, uiSchema)
+			if err == nil && test.data != "" {
+				data, _ := gabs.ParseJSON([]byte(test.data))
+				_, err = f.Bind(data)
+			}
+
+			var formErr *form.FormError
+			if !errors.As(err, &formErr) {
+				t.Fatalf("expected *FormError, got %v", err)
+			}
+			if !errors.Is(err, test.sentinel) {
+				t.Errorf("expected %v, got %v", test.sentinel, err)
+			}
+			if formErr.Stage != test.stage || formErr.Scope != test.scope {
+				t.Errorf("expected stage %q and scope %q, got %q and %q", test.stage, test.scope, formErr.Stage, formErr.Scope)
+			}
+		})
+	}
+}
+
+func TestReadFormErrors(t *testing.T) {
+	_, err := form.ReadForm(url.Values{
+		"/name":       {"John"},
+		"/name/first": {"John"},
+	})
+
+	var formErr *form.FormError
+	if !errors.As(err, &formErr) || !errors.Is(err, form.ErrInvalidData) {
+		t.Fatalf("expected ErrInvalidData, got %v", err)
+	}
+	if formErr.Stage != form.StageVerify || formErr.Scope != "/name/first" {
+		t.Errorf("unexpected error %v", formErr)
+	}
+}
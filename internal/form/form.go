@@ -1,18 +1,23 @@
 package form
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"io/fs"
+	"log/slog"
 	"net/url"
-	"path"
-	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	gabs "github.com/Jeffail/gabs/v2"
+	"github.com/TobiEiss/go-jsonforms/internal/assets"
 	"github.com/TobiEiss/go-jsonforms/models"
 )
 
@@ -27,191 +32,184 @@ var funcs = template.FuncMap{
 		}
 		return string(b), nil
 	},
+	// value formats the bound value of a control as input value
+	"value": func(v interface{}) string {
+		if v == nil {
+			return ""
+		}
+		return labelValue(v)
+	},
+	"inputType": inputType,
+	"selected": func(value, option interface{}) bool {
+		return value != nil && labelValue(value) == labelValue(option)
+	},
+	"link":         Link,
+	"placeholders": placeholders,
+	"confirmID":    confirmID,
+	// script is the embedded behaviour of the pages, for pages without assets
+	"script": func() template.JS {
+		a, _ := assets.Lookup(assets.Script)
+		return template.JS(a.Bytes())
+	},
+}
+
+// Link joins the base path of the page and a link to a URL. Absolute URLs
+// (with scheme or host) are kept as they are.
+func Link(base, link string) string {
+	if u, err := url.Parse(link); err == nil && (u.Scheme != "" || u.Host != "") {
+		return link
+	}
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(link, "/")
+}
+
+// AssetLink links an embedded asset in a page.
+type AssetLink struct {
+	URL       string
+	Integrity string
+}
+
+// assetLinks links the embedded assets below the assets path of the page, nil
+// if it has none. htmx is only linked once it is vendored.
+func assetLinks(page Page) map[string]*AssetLink {
+	return linkAssets(page.BasePath, page.AssetsPath, map[string]string{"htmx": assets.Htmx, "css": assets.Css, "js": assets.Script})
+}
+
+// linkAssets links the embedded assets of names by key, nil without assets
+// path.
+func linkAssets(base, assetsPath string, names map[string]string) map[string]*AssetLink {
+	if assetsPath == "" {
+		return nil
+	}
+	links := map[string]*AssetLink{}
+	for key, name := range names {
+		if a, ok := assets.Lookup(name); ok {
+			links[key] = &AssetLink{
+				URL:       Link(base, Link(assetsPath, a.URL())),
+				Integrity: a.Integrity,
+			}
+		}
+	}
+	return links
+}
+
+// CleanBasePath returns base with a leading and without trailing slash, or
+// "" for the root.
+func CleanBasePath(base string) string {
+	base = strings.Trim(base, "/")
+	if base == "" {
+		return ""
+	}
+	return "/" + base
+}
+
+// inputType maps the schema of a control to the type of its input.
+func inputType(s *Schema) string {
+	switch {
+	case s.Format == "date", s.Format == "time", s.Format == "email":
+		return s.Format
+	case s.Format == "date-time":
+		return "datetime-local"
+	case s.Type == "integer", s.Type == "number":
+		return "number"
+	case s.Type == "boolean":
+		return "checkbox"
+	}
+	return "text"
 }
 
+// Page holds the settings of the page around the form.
+type Page struct {
+	// BasePath prefixes every generated URL, e.g. "/admin/settings"
+	BasePath string
+	// AssetsPath is where the embedded assets are served, see assets.Handler.
+	// Without one, pages load htmx and the stylesheet from unpkg.
+	AssetsPath string
+	// Nonce is set on the inline style and script tags for a Content
+	// Security Policy, see the CSP of the root package.
+	Nonce        string
+	Menu         []models.MenuItem
+	PostLink     string
+	CssPath      string
+	LogoPath     string
+	Confirmation models.Confirmation
+	// Actions are the submit buttons of the form
+	Actions []models.Action
+	// CSRFToken is submitted with the form, see CSRFField
+	CSRFToken string
+}
+
+// Form is a form compiled from schema and UI schema into a tree of nodes.
+// Bind and Render never modify it and are safe for concurrent use.
 type Form struct {
 	schema             *gabs.Container
 	uiSchema           *gabs.Container
-	data               *gabs.Container
-	menu               []models.MenuItem
-	postLink           string
-	cssPath            string
-	logoPath           string
-	confirmation       models.Confirmation
-	customTemplateFS   embed.FS
+	tree               Node
+	customTemplateFS   fs.FS
 	customTemplateDir  string
+	templateKey        string
 	useCustomTemplates bool
 	customTemplateExt  string
+	logger             *slog.Logger
 }
 
-func NewForm(schema, uiSchema *gabs.Container) (*Form, error) {
-	form := &Form{schema: schema, uiSchema: uiSchema}
+func NewForm(schema, uiSchema *gabs.Container, opts ...Option) (*Form, error) {
+	form := &Form{schema: ResolveRefs(schema), uiSchema: uiSchema, customTemplateExt: "html", logger: DiscardLogger}
+	for _, opt := range opts {
+		opt(form)
+	}
 	err := form.setup()
 	return form, err
 }
 
-func NewFormWithCustomTemplates(schema, uiSchema *gabs.Container, templateFS embed.FS, templateDir string, useCustom bool) (*Form, error) {
+func NewFormWithCustomTemplates(schema, uiSchema *gabs.Container, templateFS fs.FS, templateDir string, useCustom bool, opts ...Option) (*Form, error) {
 	form := &Form{
-		schema:             schema,
+		schema:             ResolveRefs(schema),
 		uiSchema:           uiSchema,
 		customTemplateFS:   templateFS,
 		customTemplateDir:  templateDir,
 		useCustomTemplates: useCustom,
+		customTemplateExt:  "html",
+		logger:             DiscardLogger,
+	}
+	for _, opt := range opts {
+		opt(form)
+	}
+	if useCustom && form.templateKey == "" && !identifiable(templateFS) {
+		return nil, newError(StageSetup, "", ErrTemplate, "templates of %T need a template key", templateFS)
 	}
 	err := form.setup()
 	return form, err
 }
 
 func (f *Form) setup() error {
-	var err error
-
-	// add schema-information as schema to every control
-	iterateObj(f.uiSchema, "type", "Control", func(c *gabs.Container) {
-		scope, ok := c.Path("scope").Data().(string)
-		if !ok {
-			err = errors.New(fmt.Sprintf("in %v is no scope", c))
-		}
-
-		for k, v := range f.schema.Path(gabsPath(scope, true)).ChildrenMap() {
-			// "simple" (not nested) object
-			if len(v.Children()) == 0 {
-				c.SetP(v, fmt.Sprintf("schema.%s", k))
-			}
-			// arrays (for e.g. items)
-			if _, err := v.ArrayCount(); err == nil {
-				c.SetP(v, fmt.Sprintf("schema.%s", k))
-			}
-		}
-	})
-
-	// add HTML-col-tag
-	iterateObj(f.uiSchema, "type", nil, func(c *gabs.Container) {
-		cType, ok := c.Path("type").Data().(string)
-		if !ok {
-			return
-		}
-
-		if cType != "HorizontalLayout" && cType != "VerticalLayout" {
-			return
-		}
-
-		arrayCount, err := c.ArrayCountP("elements")
-		if err != nil {
-			return
-		}
-
-		col := 12
-		if cType == "HorizontalLayout" {
-			col = 12 / arrayCount
-		}
-
-		tag := fmt.Sprintf(" column col-%d", col)
-		for i := range arrayCount {
-			c.SetP(tag, fmt.Sprintf("elements.%d.schema.col", i))
-		}
-	})
-
-	return err
-}
-
-func (f *Form) BindData(data *gabs.Container) error {
-	var err error
-
-	f.data = data
-
-	// build multiple items for arrays
-	arrayObj, _ := gabs.New().Set("array")
-	iterateObj(f.uiSchema, "schema.type", arrayObj, func(c *gabs.Container) {
-		scope, ok := c.Path("scope").Data().(string)
-		if !ok {
-			err = errors.New(fmt.Sprintf("can't find scope for %v", c))
-		}
-
-		// how many data are there
-		arrayCount, err := f.data.ArrayCountP(gabsPath(scope, false))
-		if err != nil {
-			return
-		}
-
-		// create new array
-		origin := c.Path("options.detail").String()
-		for i := range arrayCount {
-			copy := strings.ReplaceAll(origin, "items/", fmt.Sprintf("%d/", i))
-			newDetails, _ := gabs.ParseJSON([]byte(copy))
-			c.ArrayAppendP(newDetails, "options.details")
-		}
-		c.DeleteP("options.detail")
-	})
-
-	// I don't know why....
-	f.uiSchema, _ = gabs.ParseJSON([]byte(f.uiSchema.String()))
-
-	// array-select options
-	iterateObj(f.uiSchema, "schema.type", "array-select", func(c *gabs.Container) {
-		scope, ok := c.Path("scope").Data().(string)
-		if !ok {
-			err = errors.New(fmt.Sprintf("in %v is no scope", c))
-		}
-
-		// create map in data
-		iterateArray(f.data, gabsPath(scope, false), func(dataElement *gabs.Container) {
-			label := []string{}
-			iterateArray(c, "options.elementLabelProps", func(labelElement *gabs.Container) {
-				path := labelElement.Data().(string)
-				label = append(label, dataElement.Path(gabsPath(path, false)).Data().(string))
-			})
-			c.SetP(dataElement, fmt.Sprintf("data.%s", strings.Join(label, " ")))
-		})
-
-		// clean pahts for scope in detail.elements
-		iterateObj(c.Path("options.detail.elements"), "type", "Control", func(control *gabs.Container) {
-			scope, ok := control.Path("scope").Data().(string)
-			if !ok {
-				err = errors.New(fmt.Sprintf("in %v is no scope", control))
-			}
-
-			control.SetP(strings.SplitN(gabsPath(scope, false), "items.", 2)[1], "scope")
-		})
-	})
-
-	// add data to every control
-	iterateObj(f.uiSchema, "type", "Control", func(c *gabs.Container) {
-		// ignore array-controls
-		schemaType := c.Path("schema.type").Data()
-		if reflect.DeepEqual(schemaType, "array") || reflect.DeepEqual(schemaType, "array-select") {
-			return
-		}
-
-		scope, ok := c.Path("scope").Data().(string)
-		if !ok {
-			err = errors.New(fmt.Sprintf("in %v is no scope", c))
-		}
-
-		if data := f.data.Path(gabsPath(scope, false)).Data(); data != nil {
-			c.SetP(data, "data")
-		}
-	})
+	tree, err := f.compile(f.uiSchema, "", 0)
+	f.tree = tree
 	return err
 }
 
-func (f *Form) SetMenu(menu []models.MenuItem) {
-	f.menu = menu
-}
-
-func (f *Form) SetCSS(cssPath string) {
-	f.cssPath = cssPath
+// Tree returns the compiled tree without data. It must not be modified.
+func (f *Form) Tree() Node {
+	return f.tree
 }
 
-func (f *Form) SetLogo(logoPath string) {
-	f.logoPath = logoPath
+// Bind binds data to a copy of the compiled tree and returns the copy.
+func (f *Form) Bind(data *gabs.Container) (Node, error) {
+	return f.BindWithErrors(data, nil)
 }
 
-func (f *Form) SetPostLink(link string) {
-	f.postLink = link
-}
+// BindWithErrors binds data like Bind and adds the messages of the
+// *FormError values in fieldErrs to the controls of their scope.
+func (f *Form) BindWithErrors(data *gabs.Container, fieldErrs error) (Node, error) {
+	var errs []error
+	if data == nil {
+		data = gabs.New()
+	}
+	if f.tree == nil {
+		return nil, nil
+	}
 
-func (f *Form) SetConfirmation(c models.Confirmation) {
-	f.confirmation = c
+	b := &binder{data: data, logger: f.logger, errs: &errs, fieldErrors: FieldErrors(fieldErrs)}
+	return b.bind(f.tree), errors.Join(errs...)
 }
 
 func (f *Form) SetCustomTemplateExt(ext string) {
@@ -221,102 +219,168 @@ func (f *Form) SetCustomTemplateExt(ext string) {
 	f.customTemplateExt = ext
 }
 
-func (f *Form) BuildContent() (string, error) {
-	return f.build("raw." + f.customTemplateExt)
+// Entries are the templates a form is rendered with.
+const (
+	// EntryIndex renders the full page
+	EntryIndex = "index"
+	// EntryContent renders the page content without head
+	EntryContent = "raw"
+	// EntryFragment renders the form element only, e.g. to swap it after
+	// a submit. Custom templates without it fall back to EntryContent.
+	EntryFragment = "fragment"
+)
+
+// RenderInput is what a single render binds and shows.
+type RenderInput struct {
+	// Entry is one of EntryIndex, EntryContent or EntryFragment
+	Entry string
+	Data  *gabs.Container
+	// Errors of the submitted data are shown next to their controls
+	Errors error
+	Page   Page
 }
 
-func (f *Form) BuildIndex() (string, error) {
-	return f.build("index." + f.customTemplateExt)
+// Render binds the data of in to a copy of the tree and writes it with the
+// page to w. On template errors, part of the output may have been written.
+func (f *Form) Render(ctx context.Context, w io.Writer, in RenderInput) error {
+	tree, err := f.BindWithErrors(in.Data, in.Errors)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return &FormError{Stage: StageRender, Err: err}
+	}
+
+	entry := in.Entry
+	if entry == "" {
+		entry = EntryContent
+	}
+	return f.execute(ctx, w, entry, tree, in.Page)
 }
 
-func (f *Form) build(file string) (string, error) {
-	var builder strings.Builder
+func (f *Form) execute(ctx context.Context, w io.Writer, entry string, tree Node, page Page) error {
 	var err error
 	var tmpl *template.Template
 
+	start := time.Now()
 	if f.useCustomTemplates {
-		tmpl, err = template.New("").Funcs(funcs).ParseFS(f.customTemplateFS, path.Join(f.customTemplateDir, "*"))
+		tmpl, err = customTemplateSet(f.templateKey, f.customTemplateFS, f.customTemplateDir)
 	} else {
 		// Use default embedded templates
-		tmpl, err = template.New("").Funcs(funcs).ParseFS(resources, "html/*")
+		tmpl, err = defaultTemplateSet()
 	}
 
 	if err != nil {
-		return builder.String(), err
+		return &FormError{Stage: StageRender, Err: fmt.Errorf("%w: %w", ErrTemplate, err)}
 	}
 
-	var uischema map[string]interface{}
-	if err := json.Unmarshal(f.uiSchema.Bytes(), &uischema); err != nil {
-		return "", err
+	file := entry + "." + f.customTemplateExt
+	if entry == EntryFragment && tmpl.Lookup(file) == nil {
+		file = EntryContent + "." + f.customTemplateExt
 	}
-
-	err = tmpl.ExecuteTemplate(&builder, file, map[string]interface{}{
-		"UISchema":     uischema,
-		"Menu":         f.menu,
-		"Css":          f.cssPath,
-		"Logo":         f.logoPath,
-		"PostLink":     f.postLink,
-		"Confirmation": f.confirmation,
+	f.logger.DebugContext(ctx, "template selected", "template", file, "custom", f.useCustomTemplates)
+
+	cw := &countingWriter{w: w}
+	err = tmpl.ExecuteTemplate(cw, file, map[string]interface{}{
+		"Tree":         tree,
+		"BasePath":     page.BasePath,
+		"Menu":         page.Menu,
+		"Css":          page.CssPath,
+		"Logo":         page.LogoPath,
+		"PostLink":     page.PostLink,
+		"Confirmation": page.Confirmation,
+		"Assets":       assetLinks(page),
+		"Nonce":        page.Nonce,
+		"Actions":      page.Actions,
+		"CSRFToken":    page.CSRFToken,
 	})
+	if err != nil {
+		return &FormError{Stage: StageRender, Err: fmt.Errorf("%w: %w", ErrTemplate, err)}
+	}
+	f.logger.DebugContext(ctx, "template rendered", "template", file, "bytes", cw.n, "duration", time.Since(start))
+
+	return nil
+}
+
+// countingWriter counts the bytes written for the log.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
 
-	return builder.String(), err
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
 }
 
-func ReadForm(urlForm url.Values) *gabs.Container {
+// ReadForm builds the submitted data from form values. The keys are either
+// UI schema scopes or data pointers as rendered into the name attributes,
+// the reserved fields like ActionField are skipped.
+func ReadForm(urlForm url.Values) (*gabs.Container, error) {
+	var errs []error
 	jsonObj := gabs.New()
 
-	for key, value := range urlForm {
-		path := gabsPath(key, false)
+	// sorted, so conflicting keys always fail the same way
+	keys := make([]string, 0, len(urlForm))
+	for key := range urlForm {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-		val := value[0]
-		if numVal, err := strconv.Atoi(val); err == nil {
-			jsonObj.SetP(numVal, path)
-		} else {
-			jsonObj.SetP(val, path)
+	for _, key := range keys {
+		if reserved(key) {
+			continue
+		}
+		tokens, err := DataTokens(key)
+		if err != nil {
+			errs = append(errs, &FormError{Stage: StageVerify, Scope: key, Err: err})
+			continue
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+
+		var val interface{} = urlForm[key][0]
+		if numVal, err := strconv.Atoi(urlForm[key][0]); err == nil {
+			val = numVal
+		}
+		if _, err := jsonObj.Set(val, tokens...); err != nil {
+			errs = append(errs, newError(StageVerify, key, ErrInvalidData, "%v", err))
 		}
 	}
 
-	return jsonObj
+	return jsonObj, errors.Join(errs...)
 }
 
 func (form *Form) UISchema() []byte {
 	return form.uiSchema.Bytes()
 }
 
-// iterateObj searches for a key and value. If value is empty, it looks only for the key
-func iterateObj(container *gabs.Container, key string, value any, operate func(c *gabs.Container)) {
-	val := container.Path(key).Data()
-	if val != nil && (value == nil || reflect.DeepEqual(val, value)) {
-		operate(container)
-	}
-
-	for _, child := range container.Children() {
-		iterateObj(child, key, value, operate)
-	}
-}
-
-func gabsPath(scope string, withProperties bool) string {
-	scope = strings.Trim(scope, "#/")
-	if !withProperties {
-		scope = strings.ReplaceAll(scope, "properties/", "")
-	}
-	path := strings.ReplaceAll(scope, "/", ".")
-	return path
-}
-
-func iterateArray(container *gabs.Container, path string, operate func(*gabs.Container)) error {
-	numberOfItems, err := container.ArrayCountP(path)
-	if err != nil {
-		fmt.Printf("err: %s %v\n", path, err)
-		return err
-	}
-
-	for i := range numberOfItems {
-		element, err := container.ArrayElementP(i, path)
-		if err != nil {
-			return err
+// CopyJSON copies decoded JSON, so data read by the builder shares nothing
+// with the caller.
+// Slices of maps, as written in Go literals, become regular JSON arrays.
+func CopyJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []map[string]interface{}:
+		a := make([]interface{}, len(v))
+		for i, val := range v {
+			a[i] = CopyJSON(val)
 		}
-		operate(element)
+		return a
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[key] = CopyJSON(val)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, val := range v {
+			a[i] = CopyJSON(val)
+		}
+		return a
+	default:
+		return v
 	}
-	return nil
 }
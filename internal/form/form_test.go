@@ -1,7 +1,10 @@
 package form_test
 
 import (
+	"encoding/json"
+	"net/url"
 	"reflect"
+	"strconv"
 	"testing"
 
 	gabs "github.com/Jeffail/gabs/v2"
@@ -29,7 +32,7 @@ func TestIteration(t *testing.T) {
 					}
 				}`,
 			uiSchema: `{
-					"type": "verticalLayout",
+					"type": "VerticalLayout",
 					"elements": [
 						{
 							"type":  "Control",
@@ -47,19 +50,31 @@ func TestIteration(t *testing.T) {
 					"postalCode": "12345"
 				}`,
 			expected: `{
-					"type": "verticalLayout",
 					"elements": [
 						{
-							"type":  "Control",
-							"scope": "#/properties/name",
+							"col": 12,
+							"enabled": true,
+							"id": "jf-elements-0",
+							"kind": "Control",
+							"name": "/name",
+							"pointer": "/elements/0",
 							"schema": {
-								"type":        "string",
-								"minlength":   3,
-								"description": "enter name"
+								"description": "enter name",
+								"minlength": 3,
+								"type": "string"
 							},
-							"data": "John Doe"
+							"scope": "#/properties/name",
+							"type": "Control",
+							"value": "John Doe",
+							"visible": true
 						}
-					]
+					],
+					"enabled": true,
+					"id": "jf",
+					"kind": "Layout",
+					"pointer": "",
+					"type": "VerticalLayout",
+					"visible": true
 				}`,
 		},
 		{
@@ -74,7 +89,7 @@ func TestIteration(t *testing.T) {
 					}
 				}`,
 			uiSchema: `{
-					"type": "verticalLayout",
+					"type": "VerticalLayout",
 					"elements": [
 						{
 							"type":  "Control",
@@ -83,17 +98,33 @@ func TestIteration(t *testing.T) {
 					]
 				}`,
 			expected: `{
-					"type": "verticalLayout",
 					"elements": [
 						{
-							"type":  "Control",
-							"scope": "#/properties/country",
+							"col": 12,
+							"enabled": true,
+							"id": "jf-elements-0",
+							"kind": "Control",
+							"name": "/country",
+							"pointer": "/elements/0",
 							"schema": {
-								"enum":        ["DE", "IT", "JP"],
-								"description": "enter country"
-							}
+								"description": "enter country",
+								"enum": [
+									"DE",
+									"IT",
+									"JP"
+								]
+							},
+							"scope": "#/properties/country",
+							"type": "Control",
+							"visible": true
 						}
-					]
+					],
+					"enabled": true,
+					"id": "jf",
+					"kind": "Layout",
+					"pointer": "",
+					"type": "VerticalLayout",
+					"visible": true
 				}`,
 		},
 		{
@@ -155,71 +186,186 @@ func TestIteration(t *testing.T) {
 				]
 			}`,
 			expected: `{
-  				"type": "VerticalLayout",
-  				"elements": [
-    				{
-      					"type": "Control",
-      					"scope": "#/properties/comments",
-      					"schema": {
-      						"type": "array",
-      						"title": "Comments",
-      						"col": " column col-12"
-      					},
-      					"options": {
-        					"elementLabelProp": "name",
-        					"details": [
-        						{
-	        						"type": "HorizontalLayout",
-	        						"elements": [
-							            {
-							              	"type": "Control",
-							              	"scope": "#/properties/comments/0/properties/message",
-							              	"schema": {
-							              		"type": "string",
-							              		"col": " column col-6"
-							              	},
-							              	"data": "This is an example message"
-							            },
-							            {
-								            "type": "Control",
-							              	"scope": "#/properties/comments/0/properties/name",
-							              	"schema": {
-							              		"type": "string",
-							              		"col": " column col-6"
-							              	},
-							              	"data": "John Doe"
-							            }
-									]
-	        					},
-        						{
-	        						"type": "HorizontalLayout",
-	        						"elements": [
-							            {
-							              	"type": "Control",
-							              	"scope": "#/properties/comments/1/properties/message",
-							              	"schema": {
-							              		"type": "string",
-							              		"col": " column col-6"
-							              	},
-							              	"data": "Another message"
-							            },
-							            {
-								            "type": "Control",
-							              	"scope": "#/properties/comments/1/properties/name",
-							              	"schema": {
-							              		"type": "string",
-							              		"col": " column col-6"
-							              	},
-							              	"data": "Max Mustermann"
-							            }
-									]
-	        					}
-	        					
-        					]
+					"elements": [
+						{
+							"col": 12,
+							"detail": {
+								"elements": [
+									{
+										"col": 6,
+										"enabled": true,
+										"id": "jf-elements-0-options-detail-elements-0",
+										"kind": "Control",
+										"pointer": "/elements/0/options/detail/elements/0",
+										"schema": {
+											"type": "string"
+										},
+										"scope": "#/properties/comments/items/properties/message",
+										"type": "Control",
+										"visible": true
+									},
+									{
+										"col": 6,
+										"enabled": true,
+										"id": "jf-elements-0-options-detail-elements-1",
+										"kind": "Control",
+										"pointer": "/elements/0/options/detail/elements/1",
+										"schema": {
+											"type": "string"
+										},
+										"scope": "#/properties/comments/items/properties/name",
+										"type": "Control",
+										"visible": true
+									}
+								],
+								"enabled": true,
+								"id": "jf-elements-0-options-detail",
+								"kind": "Layout",
+								"pointer": "/elements/0/options/detail",
+								"type": "HorizontalLayout",
+								"visible": true
+							},
+							"enabled": true,
+							"id": "jf-elements-0",
+							"items": [
+								{
+									"elements": [
+										{
+											"col": 6,
+											"enabled": true,
+											"id": "jf-elements-0-options-detail-elements-0-0",
+											"kind": "Control",
+											"name": "/comments/0/message",
+											"pointer": "/elements/0/options/detail/elements/0",
+											"schema": {
+												"type": "string"
+											},
+											"scope": "#/properties/comments/0/properties/message",
+											"type": "Control",
+											"value": "This is an example message",
+											"visible": true
+										},
+										{
+											"col": 6,
+											"enabled": true,
+											"id": "jf-elements-0-options-detail-elements-1-0",
+											"kind": "Control",
+											"name": "/comments/0/name",
+											"pointer": "/elements/0/options/detail/elements/1",
+											"schema": {
+												"type": "string"
+											},
+											"scope": "#/properties/comments/0/properties/name",
+											"type": "Control",
+											"value": "John Doe",
+											"visible": true
+										}
+									],
+									"enabled": true,
+									"id": "jf-elements-0-options-detail-0",
+									"kind": "Layout",
+									"pointer": "/elements/0/options/detail",
+									"type": "HorizontalLayout",
+									"visible": true
+								},
+								{
+									"elements": [
+										{
+											"col": 6,
+											"enabled": true,
+											"id": "jf-elements-0-options-detail-elements-0-1",
+											"kind": "Control",
+											"name": "/comments/1/message",
+											"pointer": "/elements/0/options/detail/elements/0",
+											"schema": {
+												"type": "string"
+											},
+											"scope": "#/properties/comments/1/properties/message",
+											"type": "Control",
+											"value": "Another message",
+											"visible": true
+										},
+										{
+											"col": 6,
+											"enabled": true,
+											"id": "jf-elements-0-options-detail-elements-1-1",
+											"kind": "Control",
+											"name": "/comments/1/name",
+											"pointer": "/elements/0/options/detail/elements/1",
+											"schema": {
+												"type": "string"
+											},
+											"scope": "#/properties/comments/1/properties/name",
+											"type": "Control",
+											"value": "Max Mustermann",
+											"visible": true
+										}
+									],
+									"enabled": true,
+									"id": "jf-elements-0-options-detail-1",
+									"kind": "Layout",
+									"pointer": "/elements/0/options/detail",
+									"type": "HorizontalLayout",
+									"visible": true
+								}
+							],
+							"kind": "ArrayControl",
+							"label": "Comments",
+							"name": "/comments",
+							"options": {
+								"detail": {
+									"elements": [
+										{
+											"scope": "#/properties/comments/items/properties/message",
+											"type": "Control"
+										},
+										{
+											"scope": "#/properties/comments/items/properties/name",
+											"type": "Control"
+										}
+									],
+									"type": "HorizontalLayout"
+								},
+								"elementLabelProp": "name"
+							},
+							"pointer": "/elements/0",
+							"schema": {
+								"items": {
+									"properties": {
+										"message": {
+											"type": "string"
+										},
+										"name": {
+											"type": "string"
+										}
+									},
+									"type": "object"
+								},
+								"title": "Comments",
+								"type": "array"
+							},
+							"scope": "#/properties/comments",
+							"type": "Control",
+							"value": [
+								{
+									"message": "This is an example message",
+									"name": "John Doe"
+								},
+								{
+									"message": "Another message",
+									"name": "Max Mustermann"
+								}
+							],
+							"visible": true
 						}
-					}
-				]
-  			}`,
+					],
+					"enabled": true,
+					"id": "jf",
+					"kind": "Layout",
+					"pointer": "",
+					"type": "VerticalLayout",
+					"visible": true
+				}`,
 		},
 		{
 			testStep: "array-select",
@@ -282,55 +428,131 @@ func TestIteration(t *testing.T) {
 				]
 			}`,
 			expected: `{
-  				"type": "VerticalLayout",
-  				"elements": [
-    				{
-      					"type": "Control",
-      					"scope": "#/properties/comments",
-      					"schema": {
-      						"type": "array-select",
-      						"title": "Comments",
-      						"col": " column col-12"
-      					},
-      					"data": {
-							"John Doe": {
-				    			"name": "John Doe",
-				      			"message": "This is an example message"
-				    		},
-				    		"Max Mustermann": {
-				      			"name": "Max Mustermann",
-				      			"message": "Another message"
-				    		}
-						},
-						"options": {
-        					"elementLabelProps": [
-        						"name"
-        					],
-        					"detail": {
-        						"type": "HorizontalLayout",
-        						"elements": [
-						            {
-						              	"type": "Control",
-						              	"scope": "message",
-						              	"schema": {
-						              		"type": "string",
-						              		"col": " column col-6"
-						              	}
-						            },
-						            {
-							            "type": "Control",
-						              	"scope": "name",
-						              	"schema": {
-						              		"type": "string",
-						              		"col": " column col-6"
-						              	}
-						            }
+					"elements": [
+						{
+							"choices": [
+								{
+									"element": {
+										"message": "This is an example message",
+										"name": "John Doe"
+									},
+									"key": "0",
+									"label": "John Doe"
+								},
+								{
+									"element": {
+										"message": "Another message",
+										"name": "Max Mustermann"
+									},
+									"key": "1",
+									"label": "Max Mustermann"
+								}
+							],
+							"col": 12,
+							"detail": {
+								"elements": [
+									{
+										"col": 6,
+										"enabled": true,
+										"id": "jf-elements-0-options-detail-elements-0",
+										"kind": "Control",
+										"name": "/comments/0/message",
+										"path": "message",
+										"pointer": "/elements/0/options/detail/elements/0",
+										"schema": {
+											"type": "string"
+										},
+										"scope": "#/properties/comments/items/properties/message",
+										"type": "Control",
+										"value": "This is an example message",
+										"visible": true
+									},
+									{
+										"col": 6,
+										"enabled": true,
+										"id": "jf-elements-0-options-detail-elements-1",
+										"kind": "Control",
+										"name": "/comments/0/name",
+										"path": "name",
+										"pointer": "/elements/0/options/detail/elements/1",
+										"schema": {
+											"type": "string"
+										},
+										"scope": "#/properties/comments/items/properties/name",
+										"type": "Control",
+										"value": "John Doe",
+										"visible": true
+									}
+								],
+								"enabled": true,
+								"id": "jf-elements-0-options-detail",
+								"kind": "Layout",
+								"pointer": "/elements/0/options/detail",
+								"type": "HorizontalLayout",
+								"visible": true
+							},
+							"enabled": true,
+							"id": "jf-elements-0",
+							"kind": "ArrayControl",
+							"label": "Comments",
+							"name": "/comments",
+							"options": {
+								"detail": {
+									"elements": [
+										{
+											"scope": "#/properties/comments/items/properties/message",
+											"type": "Control"
+										},
+										{
+											"scope": "#/properties/comments/items/properties/name",
+											"type": "Control"
+										}
+									],
+									"type": "HorizontalLayout"
+								},
+								"elementLabelProps": [
+									"name"
 								]
-        					}
+							},
+							"pointer": "/elements/0",
+							"schema": {
+								"items": {
+									"properties": {
+										"message": {
+											"type": "string"
+										},
+										"name": {
+											"type": "string"
+										}
+									},
+									"type": "object"
+								},
+								"title": "Comments",
+								"type": "array-select"
+							},
+							"scope": "#/properties/comments",
+							"select": true,
+							"type": "Control",
+							"value": [
+								{
+									"message": "This is an example message",
+									"name": "John Doe"
+								},
+								{
+									"message": "Another message",
+									"name": "Max Mustermann"
+								}
+							],
+							"visible": true
 						}
-					}
-				]
-  			}`,
+					],
+					"enabled": true,
+					"id": "jf",
+					"kind": "Layout",
+					"pointer": "",
+					"type": "VerticalLayout",
+					"visible": true
+				}`,
 		},
 		{
 			testStep: "array-select-nested",
@@ -402,59 +624,144 @@ func TestIteration(t *testing.T) {
 				]
 			}`,
 			expected: `{
-  				"type": "VerticalLayout",
-  				"elements": [
-    				{
-      					"type": "Control",
-      					"scope": "#/properties/comments",
-      					"schema": {
-      						"type": "array-select",
-      						"title": "Comments",
-      						"col": " column col-12"
-      					},
-      					"data": {
-							"John Doe": {
-				    			"person": {
-				    				"name": "John Doe"
-				    			},
-				      			"message": "This is an example message"
-				    		},
-				    		"Max Mustermann": {
-				    			"person": {
-				    				"name": "Max Mustermann"
-				    			},
-				      			"message": "Another message"
-				    		}
-						},
-						"options": {
-        					"elementLabelProps": [
-        						"person.name"
-        					],
-        					"detail": {
-        						"type": "HorizontalLayout",
-        						"elements": [
-						            {
-						              	"type": "Control",
-						              	"scope": "message",
-						              	"schema": {
-						              		"type": "string",
-						              		"col": " column col-6"
-						              	}
-						            },
-						            {
-							            "type": "Control",
-						              	"scope": "person.name",
-						              	"schema": {
-						              		"type": "string",
-						              		"col": " column col-6"
-						              	}
-						            }
+					"elements": [
+						{
+							"choices": [
+								{
+									"element": {
+										"message": "This is an example message",
+										"person": {
+											"name": "John Doe"
+										}
+									},
+									"key": "0",
+									"label": "John Doe"
+								},
+								{
+									"element": {
+										"message": "Another message",
+										"person": {
+											"name": "Max Mustermann"
+										}
+									},
+									"key": "1",
+									"label": "Max Mustermann"
+								}
+							],
+							"col": 12,
+							"detail": {
+								"elements": [
+									{
+										"col": 6,
+										"enabled": true,
+										"id": "jf-elements-0-options-detail-elements-0",
+										"kind": "Control",
+										"name": "/comments/0/message",
+										"path": "message",
+										"pointer": "/elements/0/options/detail/elements/0",
+										"schema": {
+											"type": "string"
+										},
+										"scope": "#/properties/comments/items/properties/message",
+										"type": "Control",
+										"value": "This is an example message",
+										"visible": true
+									},
+									{
+										"col": 6,
+										"enabled": true,
+										"id": "jf-elements-0-options-detail-elements-1",
+										"kind": "Control",
+										"name": "/comments/0/person/name",
+										"path": "person/name",
+										"pointer": "/elements/0/options/detail/elements/1",
+										"schema": {
+											"type": "string"
+										},
+										"scope": "#/properties/comments/items/properties/person/properties/name",
+										"type": "Control",
+										"value": "John Doe",
+										"visible": true
+									}
+								],
+								"enabled": true,
+								"id": "jf-elements-0-options-detail",
+								"kind": "Layout",
+								"pointer": "/elements/0/options/detail",
+								"type": "HorizontalLayout",
+								"visible": true
+							},
+							"enabled": true,
+							"id": "jf-elements-0",
+							"kind": "ArrayControl",
+							"label": "Comments",
+							"name": "/comments",
+							"options": {
+								"detail": {
+									"elements": [
+										{
+											"scope": "#/properties/comments/items/properties/message",
+											"type": "Control"
+										},
+										{
+											"scope": "#/properties/comments/items/properties/person/properties/name",
+											"type": "Control"
+										}
+									],
+									"type": "HorizontalLayout"
+								},
+								"elementLabelProps": [
+									"person.name"
 								]
-        					}
+							},
+							"pointer": "/elements/0",
+							"schema": {
+								"items": {
+									"properties": {
+										"message": {
+											"type": "string"
+										},
+										"person": {
+											"properties": {
+												"name": {
+													"type": "string"
+												}
+											},
+											"type": "object"
+										}
+									},
+									"type": "object"
+								},
+								"title": "Comments",
+								"type": "array-select"
+							},
+							"scope": "#/properties/comments",
+							"select": true,
+							"type": "Control",
+							"value": [
+								{
+									"message": "This is an example message",
+									"person": {
+										"name": "John Doe"
+									}
+								},
+								{
+									"message": "Another message",
+									"person": {
+										"name": "Max Mustermann"
+									}
+								}
+							],
+							"visible": true
 						}
-					}
-				]
-  			}`,
+					],
+					"enabled": true,
+					"id": "jf",
+					"kind": "Layout",
+					"pointer": "",
+					"type": "VerticalLayout",
+					"visible": true
+				}`,
 		},
 	}
 
@@ -464,12 +771,12 @@ func TestIteration(t *testing.T) {
 			uischema, _ := gabs.ParseJSON([]byte(test.uiSchema))
 			data, _ := gabs.ParseJSON([]byte(test.data))
 
-			form, err := form.NewForm(schema, uischema)
+			f, err := form.NewForm(schema, uischema)
 			if err != nil {
-				t.Error(err)
+				t.Fatal(err)
 			}
 
-			err = form.BindData(data)
+			tree, err := f.Bind(data)
 			if err != nil {
 				t.Error(err)
 			}
@@ -482,11 +789,237 @@ func TestIteration(t *testing.T) {
 				return obj.String()
 			}
 
-			uiSchemaString := toJsonString(form.UISchema())
+			b, err := json.Marshal(tree)
+			if err != nil {
+				t.Fatal(err)
+			}
+			treeString := toJsonString(b)
 			expectedString := toJsonString([]byte(test.expected))
-			if !reflect.DeepEqual(uiSchemaString, expectedString) {
-				t.Errorf("not equal:\n%s\n%s", uiSchemaString, expectedString)
+			if !reflect.DeepEqual(treeString, expectedString) {
+				t.Errorf("not equal:\n%s\n%s", treeString, expectedString)
 			}
 		})
 	}
 }
+
+func TestArraySelectLabels(t *testing.T) {
+	tests := []struct {
+		testStep  string
+		options   string
+		data      string
+		expected  []string
+		expectErr bool
+	}{
+		{
+			testStep: "numbers and bools",
+			options:  `{"elementLabelProps": ["id", "active"]}`,
+			data:     `{"items": [{"id": 7, "active": true}, {"id": 1.5, "active": false}]}`,
+			expected: []string{"7 true", "1.5 false"},
+		},
+		{
+			testStep: "label format",
+			options:  `{"elementLabelProps": ["last", "first"], "labelFormat": "%s, %s"}`,
+			data:     `{"items": [{"first": "John", "last": "Doe"}]}`,
+			expected: []string{"Doe, John"},
+		},
+		{
+			testStep: "duplicate labels",
+			options:  `{"elementLabelProp": "name"}`,
+			data:     `{"items": [{"name": "John"}, {"name": "John"}]}`,
+			expected: []string{"John", "John"},
+		},
+		{
+			testStep:  "missing prop",
+			options:   `{"elementLabelProps": ["name"]}`,
+			data:      `{"items": [{"name": "John"}, {"id": 2}]}`,
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testStep, func(t *testing.T) {
+			schema, _ := gabs.ParseJSON([]byte(`{
+				"properties": {
+					"items": {
+						"type": "array-select",
+						"items": {"type": "object"}
+					}
+				}
+			}`))
+			uischema, _ := gabs.ParseJSON([]byte(`{
+				"type": "VerticalLayout",
+				"elements": [
+					{
+						"type": "Control",
+						"scope": "#/properties/items",
+						"options": ` + test.options + `
+					}
+				]
+			}`))
+			data, _ := gabs.ParseJSON([]byte(test.data))
+
+			f, err := form.NewForm(schema, uischema)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tree, err := f.Bind(data)
+			if test.expectErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			choices := tree.(*form.Layout).Elements[0].(*form.ArrayControl).Choices
+			if len(choices) != len(test.expected) {
+				t.Fatalf("expected %d choices, got %d", len(test.expected), len(choices))
+			}
+			for i, choice := range choices {
+				if choice.Label != test.expected[i] {
+					t.Errorf("choice %d: expected label %q, got %q", i, test.expected[i], choice.Label)
+				}
+				if choice.Key != strconv.Itoa(i) {
+					t.Errorf("choice %d: expected key %q, got %q", i, strconv.Itoa(i), choice.Key)
+				}
+			}
+		})
+	}
+}
+
+func TestUnusualPropertyNames(t *testing.T) {
+	schema, _ := gabs.ParseJSON([]byte(`{
+		"properties": {
+			"myproperties": {"type": "string", "title": "Properties"},
+			"a.b": {"type": "string", "title": "Dotted"},
+			"c/d": {"type": "string", "title": "Slashed"},
+			"items": {"type": "string", "title": "Items"}
+		}
+	}`))
+	uischema, _ := gabs.ParseJSON([]byte(`{
+		"type": "VerticalLayout",
+		"elements": [
+			{"type": "Control", "scope": "#/properties/myproperties"},
+			{"type": "Control", "scope": "#/properties/a.b"},
+			{"type": "Control", "scope": "#/properties/c~1d"},
+			{"type": "Control", "scope": "#/properties/items"}
+		]
+	}`))
+	data, _ := gabs.ParseJSON([]byte(`{"myproperties": "1", "a.b": "2", "c/d": "3", "items": "4"}`))
+
+	f, err := form.NewForm(schema, uischema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := f.Bind(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct{ label, name, value string }{
+		{"Properties", "/myproperties", "1"},
+		{"Dotted", "/a.b", "2"},
+		{"Slashed", "/c~1d", "3"},
+		{"Items", "/items", "4"},
+	}
+	values := url.Values{}
+	for i, e := range expected {
+		control := tree.(*form.Layout).Elements[i].(*form.Control)
+		if control.Label != e.label {
+			t.Errorf("control %d: expected label %q, got %q", i, e.label, control.Label)
+		}
+		if control.Name != e.name {
+			t.Errorf("control %d: expected name %q, got %q", i, e.name, control.Name)
+		}
+		if control.Value != e.value {
+			t.Errorf("control %d: expected value %q, got %v", i, e.value, control.Value)
+		}
+		values.Set(e.name, e.value+"x")
+	}
+
+	// the names round-trip through ReadForm
+	read, err := form.ReadForm(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"myproperties", "a.b", "c/d", "items"} {
+		if !read.Exists(key) {
+			t.Errorf("expected key %q in %s", key, read.String())
+		}
+	}
+}
+
+func TestReadFormReserved(t *testing.T) {
+	read, err := form.ReadForm(url.Values{"/name": {"John"}, form.ActionField: {"save"}, form.CSRFField: {"tok"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]interface{}{"name": "John"}; !reflect.DeepEqual(read.Data(), expected) {
+		t.Errorf("expected %v without the reserved fields, got %s", expected, read.String())
+	}
+}
+
+func TestTreeRulesAndDetail(t *testing.T) {
+	schema, _ := gabs.ParseJSON([]byte(`{
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "title": "Name"},
+			"hidden": {"type": "boolean"},
+			"tags": {
+				"type": "array",
+				"items": {"type": "object", "properties": {"b": {"type": "string"}, "a": {"type": "string"}}}
+			}
+		}
+	}`))
+	uischema, _ := gabs.ParseJSON([]byte(`{
+		"type": "VerticalLayout",
+		"elements": [
+			{
+				"type": "Control",
+				"scope": "#/properties/name",
+				"rule": {"effect": "HIDE", "condition": {"scope": "#/properties/hidden", "schema": {"const": true}}}
+			},
+			{"type": "Control", "scope": "#/properties/tags"}
+		]
+	}`))
+	data, _ := gabs.ParseJSON([]byte(`{"hidden": true, "tags": [{"a": "1", "b": "2"}]}`))
+
+	f, err := form.NewForm(schema, uischema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := f.Bind(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	elements := tree.(*form.Layout).Elements
+	name := elements[0].(*form.Control)
+	if name.Visible || !name.Required || name.Label != "Name" {
+		t.Errorf("expected hidden required control labelled Name, got %+v", name)
+	}
+
+	// the detail is generated from the item properties, sorted by name
+	tags := elements[1].(*form.ArrayControl)
+	if len(tags.Items) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(tags.Items))
+	}
+	var names []string
+	form.Walk(tags.Items[0], func(n form.Node) bool {
+		if c, ok := n.(*form.Control); ok {
+			names = append(names, c.Name)
+		}
+		return true
+	})
+	if !reflect.DeepEqual(names, []string{"/tags/0/a", "/tags/0/b"}) {
+		t.Errorf("unexpected controls %v", names)
+	}
+
+	// binding never touches the compiled tree
+	if f.Tree().(*form.Layout).Elements[0].(*form.Control).Value != nil {
+		t.Error("compiled tree was modified")
+	}
+}
@@ -0,0 +1,29 @@
+package form
+
+import (
+	"context"
+	"log/slog"
+)
+
+// discardHandler drops every record, so forms stay silent by default.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// DiscardLogger is a logger without output.
+var DiscardLogger = slog.New(discardHandler{})
+
+// Option configures a Form on creation.
+type Option func(*Form)
+
+// WithLogger sets the logger for debug events of the form pipeline.
+func WithLogger(logger *slog.Logger) Option {
+	return func(f *Form) {
+		if logger != nil {
+			f.logger = logger
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package form
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/TobiEiss/go-jsonforms/internal/assets"
+)
+
+// PlaygroundPage is the page of the playground of the root package, with the
+// sources the editors start with.
+type PlaygroundPage struct {
+	BasePath   string
+	AssetsPath string
+	Schema     string
+	UISchema   string
+	Data       string
+}
+
+// RenderPlayground writes the playground page to w.
+func RenderPlayground(w io.Writer, page PlaygroundPage) error {
+	return executePage(w, "playground.html", map[string]interface{}{
+		"BasePath": page.BasePath,
+		"Assets":   linkAssets(page.BasePath, page.AssetsPath, map[string]string{"css": assets.Css, "js": assets.PlaygroundScript, "playground": assets.PlaygroundCss}),
+		"Schema":   page.Schema,
+		"UISchema": page.UISchema,
+		"Data":     page.Data,
+	})
+}
+
+// RenderPreview writes content, a rendered form without page, as document
+// with the embedded stylesheet and script, for the preview of the playground.
+func RenderPreview(w io.Writer, base, assetsPath string, content []byte) error {
+	return executePage(w, "preview.html", map[string]interface{}{
+		"Assets":  linkAssets(base, assetsPath, map[string]string{"css": assets.Css, "js": assets.Script}),
+		"Content": template.HTML(content),
+	})
+}
+
+func executePage(w io.Writer, name string, data map[string]interface{}) error {
+	tmpl, err := defaultTemplateSet()
+	if err == nil {
+		err = tmpl.ExecuteTemplate(w, name, data)
+	}
+	if err != nil {
+		return &FormError{Stage: StageRender, Err: fmt.Errorf("%w: %w", ErrTemplate, err)}
+	}
+	return nil
+}
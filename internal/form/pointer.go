@@ -0,0 +1,81 @@
+package form
+
+import (
+	"fmt"
+	"strings"
+
+	gabs "github.com/Jeffail/gabs/v2"
+)
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// ScopeTokens splits a UI schema scope like "#/properties/a~1b" into its
+// unescaped RFC 6901 reference tokens.
+func ScopeTokens(scope string) ([]string, error) {
+	if !strings.HasPrefix(scope, "#") {
+		return nil, fmt.Errorf("%w: %q is no JSON pointer fragment", ErrInvalidScope, scope)
+	}
+	tokens, err := gabs.JSONPointerToSlice(strings.TrimPrefix(scope, "#"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q: %v", ErrInvalidScope, scope, err)
+	}
+	return tokens, nil
+}
+
+// DataTokens resolves a scope to the tokens of the data it points at.
+// Schema scopes ("#/properties/a/items/properties/b") drop their keywords,
+// everything else is read as a (relative) data pointer ("/a/0/b" or "a/0/b").
+func DataTokens(scope string) ([]string, error) {
+	if !strings.HasPrefix(scope, "#") {
+		if scope == "" {
+			return nil, nil
+		}
+		tokens, err := gabs.JSONPointerToSlice("/" + strings.TrimPrefix(scope, "/"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidScope, scope, err)
+		}
+		return tokens, nil
+	}
+
+	tokens, err := ScopeTokens(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]string, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "properties":
+			// the keyword is followed by the property name, whatever it is
+			if i+1 < len(tokens) {
+				i++
+				data = append(data, tokens[i])
+			}
+		case "items":
+			// array items have no own segment in the data
+		default:
+			data = append(data, tokens[i])
+		}
+	}
+	return data, nil
+}
+
+// EscapeToken escapes a single reference token for use in a JSON pointer.
+func EscapeToken(token string) string {
+	return pointerEscaper.Replace(token)
+}
+
+// Pointer joins reference tokens to a JSON pointer ("/a~1b/0").
+func Pointer(tokens []string) string {
+	var builder strings.Builder
+	for _, token := range tokens {
+		builder.WriteString("/")
+		builder.WriteString(EscapeToken(token))
+	}
+	return builder.String()
+}
+
+// relativePointer joins reference tokens to a JSON pointer without leading slash.
+func relativePointer(tokens []string) string {
+	return strings.TrimPrefix(Pointer(tokens), "/")
+}
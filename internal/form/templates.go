@@ -0,0 +1,147 @@
+package form
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"reflect"
+	"sync"
+)
+
+var (
+	defaultTemplatesOnce sync.Once
+	defaultTemplates     *template.Template
+	defaultTemplatesErr  error
+
+	customTemplatesMu sync.RWMutex
+	// customTemplates are the parsed custom templates, oldest first
+	customTemplates []*cachedTemplates
+)
+
+// maxCustomTemplates bounds the cached custom template sets, the oldest are
+// dropped first.
+const maxCustomTemplates = 64
+
+// cachedTemplates are the parsed templates of a key. Without a key of their
+// own they are cached under their directory and tell their file systems apart;
+// the cache holds on to the file system, so its identity isn't reused.
+type cachedTemplates struct {
+	key  string
+	fsys fs.FS
+	tmpl *template.Template
+}
+
+func (c *cachedTemplates) matches(key string, fsys fs.FS) bool {
+	if c.key != key || (c.fsys == nil) != (fsys == nil) {
+		return false
+	}
+	return fsys == nil || sameFS(c.fsys, fsys)
+}
+
+// sameFS reports whether a and b are the same file system.
+func sameFS(a, b fs.FS) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	if reflect.TypeOf(a).Comparable() {
+		return a == b
+	}
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
+// identifiable reports whether sameFS can tell fsys apart from other file
+// systems: comparable values and maps, slices and functions by address.
+func identifiable(fsys fs.FS) bool {
+	t := reflect.TypeOf(fsys)
+	if t == nil {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Func:
+		return true
+	}
+	return t.Comparable()
+}
+
+// partials are the embedded templates parsed into custom sets, which may
+// define their own versions.
+var partials = []string{"html/csrf.html"}
+
+// defaultTemplateSet returns the embedded templates, parsed on first use.
+func defaultTemplateSet() (*template.Template, error) {
+	defaultTemplatesOnce.Do(func() {
+		defaultTemplates, defaultTemplatesErr = template.New("").Funcs(funcs).ParseFS(resources, "html/*")
+	})
+	return defaultTemplates, defaultTemplatesErr
+}
+
+// customTemplateSet returns the templates in dir of fsys. They are parsed once
+// and cached under key until ReloadTemplates drops them. Without key they are
+// cached under dir for fsys only.
+func customTemplateSet(key string, fsys fs.FS, dir string) (*template.Template, error) {
+	var owner fs.FS
+	if key == "" {
+		key, owner = dir, fsys
+	}
+	if tmpl, ok := lookupTemplates(key, owner); ok {
+		return tmpl, nil
+	}
+
+	customTemplatesMu.Lock()
+	defer customTemplatesMu.Unlock()
+	for _, cached := range customTemplates {
+		if cached.matches(key, owner) {
+			return cached.tmpl, nil
+		}
+	}
+
+	// the partials of the embedded templates are available to custom ones too
+	tmpl, err := template.New("").Funcs(funcs).ParseFS(resources, partials...)
+	if err == nil {
+		tmpl, err = tmpl.ParseFS(fsys, path.Join(dir, "*"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("templates %q: %w", key, err)
+	}
+	if len(customTemplates) >= maxCustomTemplates {
+		customTemplates = append(customTemplates[:0:0], customTemplates[len(customTemplates)-maxCustomTemplates+1:]...)
+	}
+	customTemplates = append(customTemplates, &cachedTemplates{key: key, fsys: owner, tmpl: tmpl})
+	return tmpl, nil
+}
+
+func lookupTemplates(key string, fsys fs.FS) (*template.Template, bool) {
+	customTemplatesMu.RLock()
+	defer customTemplatesMu.RUnlock()
+	for _, cached := range customTemplates {
+		if cached.matches(key, fsys) {
+			return cached.tmpl, true
+		}
+	}
+	return nil, false
+}
+
+// ReloadTemplates drops the cached custom templates of key, so they are parsed
+// again on the next render. A template directory drops the templates cached
+// without key for that directory, an empty key drops all custom templates.
+func ReloadTemplates(key string) {
+	customTemplatesMu.Lock()
+	defer customTemplatesMu.Unlock()
+	kept := customTemplates[:0:0]
+	for _, cached := range customTemplates {
+		if key != "" && cached.key != key {
+			kept = append(kept, cached)
+		}
+	}
+	customTemplates = kept
+}
+
+// WithTemplateKey sets the key the custom templates are cached under. Without
+// one they are cached per template directory and file system, which has to be
+// comparable or a map.
+func WithTemplateKey(key string) Option {
+	return func(f *Form) {
+		f.templateKey = key
+	}
+}
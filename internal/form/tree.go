@@ -0,0 +1,179 @@
+package form
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Node is an element of the resolved UI tree the templates render.
+type Node interface {
+	// Kind is one of "Layout", "Group", "Label", "Control" or "ArrayControl"
+	Kind() string
+	base() *Base
+}
+
+// Base holds the fields every node has.
+type Base struct {
+	// NodeKind is returned by Kind
+	NodeKind string `json:"kind"`
+	// ID is unique within the form and used as HTML id
+	ID string `json:"id"`
+	// Pointer is the JSON pointer of the element in the UI schema
+	Pointer string `json:"pointer"`
+	// Type is the UI schema type, e.g. "HorizontalLayout" or "Control"
+	Type string `json:"type"`
+	// Col is the width in a 12 column grid given by the parent layout, 0 if none
+	Col     int                    `json:"col,omitempty"`
+	Options map[string]interface{} `json:"options,omitempty"`
+	Rule    *Rule                  `json:"rule,omitempty"`
+	// Visible and Enabled are the result of the rule for the bound data
+	Visible bool `json:"visible"`
+	Enabled bool `json:"enabled"`
+}
+
+func (b *Base) base() *Base { return b }
+
+// Layout is a VerticalLayout or HorizontalLayout.
+type Layout struct {
+	Base
+	Elements []Node `json:"elements"`
+}
+
+// Group is a layout with a label.
+type Group struct {
+	Base
+	Label    string `json:"label,omitempty"`
+	Elements []Node `json:"elements"`
+}
+
+// Label is a text.
+type Label struct {
+	Base
+	Text string `json:"text"`
+}
+
+// Control is an input for a single value.
+type Control struct {
+	Base
+	// Scope is the UI schema scope, with array indices once bound
+	Scope string `json:"scope"`
+	// Name is the data pointer the value is submitted as
+	Name string `json:"name,omitempty"`
+	// Path is the pointer relative to the selected element of an array-select
+	Path     string      `json:"path,omitempty"`
+	Label    string      `json:"label,omitempty"`
+	Schema   *Schema     `json:"schema"`
+	Required bool        `json:"required,omitempty"`
+	Value    interface{} `json:"value,omitempty"`
+	Errors   []string    `json:"errors,omitempty"`
+}
+
+// ArrayControl is a control for an array, rendered as one detail per element
+// or, for array-select, as select of the elements.
+type ArrayControl struct {
+	Control
+	// Detail is the layout of a single element
+	Detail Node `json:"detail,omitempty"`
+	// Items are the bound details, one per element
+	Items []Node `json:"items,omitempty"`
+	// Select is set for array-select controls
+	Select  bool     `json:"select,omitempty"`
+	Choices []Choice `json:"choices,omitempty"`
+}
+
+// Choice is an element offered by an array-select.
+type Choice struct {
+	// Key is the index of the element
+	Key     string      `json:"key"`
+	Label   string      `json:"label"`
+	Element interface{} `json:"element"`
+}
+
+// Rule is the JSON Forms rule of an element.
+type Rule struct {
+	// Effect is one of HIDE, SHOW, ENABLE or DISABLE
+	Effect string `json:"effect"`
+	// Scope of the data the condition checks
+	Scope string `json:"scope"`
+	// Schema the data must match, only const and enum are supported
+	Schema map[string]interface{} `json:"schema,omitempty"`
+	// ExpectedValue of legacy LEAF conditions
+	ExpectedValue interface{} `json:"expectedValue,omitempty"`
+}
+
+// Schema is the resolved schema of a control.
+type Schema struct {
+	Type        string        `json:"-"`
+	Title       string        `json:"-"`
+	Description string        `json:"-"`
+	Format      string        `json:"-"`
+	Enum        []interface{} `json:"-"`
+	Default     interface{}   `json:"-"`
+	ReadOnly    bool          `json:"-"`
+	// Raw is the schema as written
+	Raw map[string]interface{} `json:"-"`
+}
+
+func newSchema(raw map[string]interface{}) *Schema {
+	s := &Schema{Raw: raw}
+	s.Type = schemaType(raw)
+	s.Title, _ = raw["title"].(string)
+	s.Description, _ = raw["description"].(string)
+	s.Format, _ = raw["format"].(string)
+	s.Enum, _ = raw["enum"].([]interface{})
+	s.Default = raw["default"]
+	s.ReadOnly, _ = raw["readOnly"].(bool)
+	return s
+}
+
+// schemaType returns the type of schema. Of a list of types, like
+// ["string", "null"] for nullable values, it is the first but "null".
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, name := range t {
+			if s, ok := name.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Raw)
+}
+
+func (b *Base) Kind() string { return b.NodeKind }
+
+// Walk calls fn for node and all nodes below, depth first. Returning false
+// skips the children of a node.
+func Walk(node Node, fn func(Node) bool) {
+	if node == nil || !fn(node) {
+		return
+	}
+	switch n := node.(type) {
+	case *Layout:
+		for _, child := range n.Elements {
+			Walk(child, fn)
+		}
+	case *Group:
+		for _, child := range n.Elements {
+			Walk(child, fn)
+		}
+	case *ArrayControl:
+		if n.Select {
+			Walk(n.Detail, fn)
+		}
+		for _, item := range n.Items {
+			Walk(item, fn)
+		}
+	}
+}
+
+// nodeID derives the HTML id of a node from its UI schema pointer.
+func nodeID(pointer string) string {
+	return "jf" + strings.NewReplacer("/", "-", "~", "_").Replace(pointer)
+}
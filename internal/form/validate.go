@@ -0,0 +1,235 @@
+package form
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	gabs "github.com/Jeffail/gabs/v2"
+)
+
+// layouts are the UI schema elements holding further elements
+var layouts = map[string]bool{
+	"VerticalLayout":   true,
+	"HorizontalLayout": true,
+	"Group":            true,
+}
+
+// Problem is a single finding in a UI schema.
+type Problem struct {
+	// Pointer is the JSON pointer to the offending UI schema element, or
+	// schema keyword for UnresolvedRefs
+	Pointer string
+	// Err wraps one of ErrInvalidUISchema, ErrMissingScope, ErrInvalidScope,
+	// ErrUnresolvedScope, ErrUnknownElementType or ErrUnresolvedRef
+	Err error
+}
+
+func (p Problem) String() string {
+	pointer := p.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return fmt.Sprintf("%s: %v", pointer, p.Err)
+}
+
+// UISchemaError lists all problems found in a UI schema.
+type UISchemaError struct {
+	Problems []Problem
+}
+
+func (e *UISchemaError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	return fmt.Sprintf("invalid uischema (%d problems):\n%s", len(e.Problems), strings.Join(lines, "\n"))
+}
+
+// Is reports every UISchemaError as ErrInvalidUISchema.
+func (e *UISchemaError) Is(target error) bool {
+	return target == ErrInvalidUISchema
+}
+
+// Unwrap gives access to the errors of the single problems.
+func (e *UISchemaError) Unwrap() []error {
+	errs := make([]error, len(e.Problems))
+	for i, p := range e.Problems {
+		errs[i] = p.Err
+	}
+	return errs
+}
+
+// ValidateUISchema checks the UI schema against the structure of JSON Forms
+// UI schemas and makes sure every scope resolves in the schema, following
+// local $refs (see ResolveRefs). All problems
+// are collected into one *UISchemaError.
+func ValidateUISchema(schema, uiSchema *gabs.Container) error {
+	v := validator{schema: ResolveRefs(schema)}
+	v.element(uiSchema, "")
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &UISchemaError{Problems: v.problems}
+}
+
+// ResolveRefs returns a copy of schema with every local $ref replaced by the
+// schema it points at, keywords next to the $ref taking precedence. Scopes,
+// required properties and submitted values are all resolved against the
+// copy. Recursive references are followed once and kept as $ref below.
+func ResolveRefs(schema *gabs.Container) *gabs.Container {
+	return gabs.Wrap(resolveRefs(schema, schema.Data(), nil))
+}
+
+// resolveRefs copies v with its references resolved. refs are the
+// references being resolved on the way to v.
+func resolveRefs(root *gabs.Container, v interface{}, refs []string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok && !slices.Contains(refs, ref) {
+			tokens, err := ScopeTokens(ref)
+			if target, ok := root.Search(tokens...).Data().(map[string]interface{}); err == nil && ok {
+				merged := make(map[string]interface{}, len(target)+len(v))
+				for key, value := range target {
+					merged[key] = value
+				}
+				for key, value := range v {
+					if key != "$ref" {
+						merged[key] = value
+					}
+				}
+				return resolveRefs(root, merged, append(refs[:len(refs):len(refs)], ref))
+			}
+		}
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = resolveRefs(root, value, refs)
+		}
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, item := range v {
+			a[i] = resolveRefs(root, item, refs)
+		}
+		return a
+	}
+	return v
+}
+
+// UnresolvedRefs returns a Problem for every $ref of the schema that does not
+// point into the schema itself. References to other documents are reported
+// as well, they are not supported.
+func UnresolvedRefs(schema *gabs.Container) []Problem {
+	var problems []Problem
+	var walk func(v interface{}, tokens []string)
+	walk = func(v interface{}, tokens []string) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := v["$ref"].(string); ok {
+				pointer := Pointer(append(tokens[:len(tokens):len(tokens)], "$ref"))
+				refTokens, err := ScopeTokens(ref)
+				if err != nil || !schema.Exists(refTokens...) && len(refTokens) > 0 {
+					problems = append(problems, Problem{Pointer: pointer, Err: fmt.Errorf("%w: %q", ErrUnresolvedRef, ref)})
+				}
+			}
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(v[key], append(tokens[:len(tokens):len(tokens)], key))
+			}
+		case []interface{}:
+			for i, item := range v {
+				walk(item, append(tokens[:len(tokens):len(tokens)], strconv.Itoa(i)))
+			}
+		}
+	}
+	walk(schema.Data(), nil)
+	return problems
+}
+
+type validator struct {
+	schema   *gabs.Container
+	problems []Problem
+}
+
+func (v *validator) add(pointer string, sentinel error, format string, args ...interface{}) {
+	err := sentinel
+	if format != "" {
+		err = fmt.Errorf("%w: %s", sentinel, fmt.Sprintf(format, args...))
+	}
+	v.problems = append(v.problems, Problem{Pointer: pointer, Err: err})
+}
+
+func (v *validator) element(c *gabs.Container, pointer string) {
+	if _, ok := c.Data().(map[string]interface{}); !ok {
+		v.add(pointer, ErrInvalidUISchema, "element is no object")
+		return
+	}
+
+	elementType, ok := c.Search("type").Data().(string)
+	if !ok {
+		v.add(pointer, ErrInvalidUISchema, "element has no type")
+		return
+	}
+
+	if options := c.Search("options"); options != nil {
+		if _, ok := options.Data().(map[string]interface{}); !ok {
+			v.add(pointer+"/options", ErrInvalidUISchema, "options is no object")
+		}
+	}
+
+	switch {
+	case layouts[elementType]:
+		if label := c.Search("label"); elementType == "Group" && label != nil {
+			if _, ok := label.Data().(string); !ok {
+				v.add(pointer+"/label", ErrInvalidUISchema, "label is no string")
+			}
+		}
+		elements, ok := c.Search("elements").Data().([]interface{})
+		if !ok {
+			v.add(pointer, ErrInvalidUISchema, "%s has no elements array", elementType)
+			return
+		}
+		for i := range elements {
+			v.element(c.Search("elements", strconv.Itoa(i)), fmt.Sprintf("%s/elements/%d", pointer, i))
+		}
+	case elementType == "Label":
+		if _, ok := c.Search("text").Data().(string); !ok {
+			v.add(pointer, ErrInvalidUISchema, "Label has no text")
+		}
+	case elementType == "Control":
+		v.control(c, pointer)
+	default:
+		v.add(pointer+"/type", ErrUnknownElementType, "%q", elementType)
+	}
+}
+
+func (v *validator) control(c *gabs.Container, pointer string) {
+	scope, ok := c.Search("scope").Data().(string)
+	if !ok {
+		v.add(pointer, ErrMissingScope, "")
+		return
+	}
+
+	tokens, err := ScopeTokens(scope)
+	if err != nil {
+		v.problems = append(v.problems, Problem{Pointer: pointer + "/scope", Err: err})
+		return
+	}
+	if _, ok := v.schema.Search(tokens...).Data().(map[string]interface{}); !ok {
+		v.add(pointer+"/scope", ErrUnresolvedScope, "%q", scope)
+		return
+	}
+
+	// detail layouts of arrays are regular UI schemas
+	if detail := c.Search("options", "detail"); detail != nil {
+		if _, ok := detail.Data().(string); !ok {
+			v.element(detail, pointer+"/options/detail")
+		}
+	}
+}
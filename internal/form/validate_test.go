@@ -0,0 +1,181 @@
+package form_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	gabs "github.com/Jeffail/gabs/v2"
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+)
+
+func TestValidateUISchema(t *testing.T) {
+	schema, _ := gabs.ParseJSON([]byte(`{
+		"properties": {
+			"name": {"type": "string"},
+			"comments": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"properties": {
+						"message": {"type": "string"}
+					}
+				}
+			}
+		}
+	}`))
+
+	tests := []struct {
+		testStep string
+		uiSchema string
+		expected []string
+	}{
+		{
+			testStep: "valid",
+			uiSchema: `{
+				"type": "VerticalLayout",
+				"elements": [
+					{"type": "Label", "text": "Person"},
+					{"type": "Control", "scope": "#/properties/name"},
+					{
+						"type": "Control",
+						"scope": "#/properties/comments",
+						"options": {
+							"detail": {
+								"type": "HorizontalLayout",
+								"elements": [
+									{"type": "Control", "scope": "#/properties/comments/items/properties/message"}
+								]
+							}
+						}
+					}
+				]
+			}`,
+		},
+		{
+			testStep: "problems",
+			uiSchema: `{
+				"type": "VerticalLayout",
+				"elements": [
+					{"type": "Control", "scope": "#/properties/unknown"},
+					{"type": "Control"},
+					{"type": "Textarea"},
+					{"type": "Group", "label": 3, "elements": [
+						{"type": "Control", "scope": "properties/name"}
+					]},
+					{
+						"type": "Control",
+						"scope": "#/properties/comments",
+						"options": {
+							"detail": {
+								"type": "HorizontalLayout",
+								"elements": [
+									{"type": "Control", "scope": "#/properties/comments/items/properties/name"}
+								]
+							}
+						}
+					},
+					{"type": "HorizontalLayout"}
+				]
+			}`,
+			expected: []string{
+				"/elements/0/scope",
+				"/elements/1",
+				"/elements/2/type",
+				"/elements/3/label",
+				"/elements/3/elements/0/scope",
+				"/elements/4/options/detail/elements/0/scope",
+				"/elements/5",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testStep, func(t *testing.T) {
+			uiSchema, _ := gabs.ParseJSON([]byte(test.uiSchema))
+
+			err := form.ValidateUISchema(schema, uiSchema)
+			if test.expected == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var uiSchemaErr *form.UISchemaError
+			if !errors.As(err, &uiSchemaErr) {
+				t.Fatalf("expected *UISchemaError, got %v", err)
+			}
+			pointers := []string{}
+			for _, p := range uiSchemaErr.Problems {
+				pointers = append(pointers, p.Pointer)
+			}
+			if !reflect.DeepEqual(pointers, test.expected) {
+				t.Errorf("not equal:\n%v\n%v", pointers, test.expected)
+			}
+		})
+	}
+}
+
+func TestUnresolvedRefs(t *testing.T) {
+	schema, _ := gabs.ParseJSON([]byte(`{
+		"properties": {
+			"self": {"$ref": "#"},
+			"address": {"$ref": "#/$defs/address"},
+			"missing": {"$ref": "#/$defs/missing"},
+			"list": {"type": "array", "items": [{"$ref": "other.json#/a"}]}
+		},
+		"$defs": {"address": {"type": "object"}}
+	}`))
+
+	var problems []string
+	for _, p := range form.UnresolvedRefs(schema) {
+		if !errors.Is(p.Err, form.ErrUnresolvedRef) {
+			t.Errorf("%s does not match ErrUnresolvedRef", p)
+		}
+		problems = append(problems, p.String())
+	}
+	expected := []string{
+		`/properties/list/items/0/$ref: unresolved $ref: "other.json#/a"`,
+		`/properties/missing/$ref: unresolved $ref: "#/$defs/missing"`,
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected %q, got %q", expected, problems)
+	}
+}
+
+func TestResolveRefs(t *testing.T) {
+	schema, _ := gabs.ParseJSON([]byte(`{
+		"properties": {
+			"home": {"$ref": "#/$defs/address", "title": "Home"},
+			"tree": {"$ref": "#/$defs/node"}
+		},
+		"$defs": {
+			"address": {"type": "object", "title": "Address", "properties": {"street": {"$ref": "#/$defs/street"}}},
+			"street": {"type": "string"},
+			"node": {"type": "object", "properties": {"child": {"$ref": "#/$defs/node"}}}
+		}
+	}`))
+
+	resolved := form.ResolveRefs(schema)
+	expected := map[string]interface{}{
+		"type":       "object",
+		"title":      "Home",
+		"properties": map[string]interface{}{"street": map[string]interface{}{"type": "string"}},
+	}
+	if home := resolved.Search("properties", "home").Data(); !reflect.DeepEqual(home, expected) {
+		t.Errorf("expected %v, got %v", expected, home)
+	}
+	// recursive references are resolved once
+	if ref := resolved.Search("properties", "tree", "properties", "child", "$ref").Data(); ref != "#/$defs/node" {
+		t.Errorf("expected the recursive $ref to be kept, got %s", resolved.Search("properties", "tree"))
+	}
+	if schema.Exists("properties", "home", "type") {
+		t.Error("the schema was modified")
+	}
+
+	uischema, _ := gabs.ParseJSON([]byte(`{"type": "Control", "scope": "#/properties/home/properties/street"}`))
+	if err := form.ValidateUISchema(schema, uischema); err != nil {
+		t.Errorf("expected the scope through the $ref to resolve, got %v", err)
+	}
+}
@@ -0,0 +1,586 @@
+package form
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	gabs "github.com/Jeffail/gabs/v2"
+)
+
+// ValidationError is a value the user has to fix. It is reported as Err of a
+// *FormError of StageVerify, the scope is the data pointer of the value.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// Is reports every ValidationError as ErrValidation.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+func invalid(tokens []string, format string, args ...interface{}) *FormError {
+	return &FormError{Stage: StageVerify, Scope: Pointer(tokens), Err: &ValidationError{Message: fmt.Sprintf(format, args...)}}
+}
+
+// Decode reads submitted form values into data typed by the schema. Empty
+// values and values without a schema are left out, unchecked checkboxes
+// become false.
+func (f *Form) Decode(values url.Values) (*gabs.Container, error) {
+	var errs []error
+	data := gabs.New()
+
+	// sorted, so conflicting keys always fail the same way
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		tokens, err := DataTokens(key)
+		if err != nil {
+			errs = append(errs, &FormError{Stage: StageVerify, Scope: key, Err: fmt.Errorf("%w: %w", ErrInvalidData, err)})
+			continue
+		}
+		if reserved(key) || len(tokens) == 0 || len(values[key]) == 0 || values[key][0] == "" {
+			continue
+		}
+		schema := schemaAt(f.schema.Data(), tokens)
+		if schema == nil {
+			continue
+		}
+
+		value, err := coerce(schema, values[key][0])
+		if err != nil {
+			errs = append(errs, invalid(tokens, "%v", err))
+			continue
+		}
+		if _, err := data.Set(value, tokens...); err != nil {
+			errs = append(errs, newError(StageVerify, key, ErrInvalidData, "%v", err))
+		}
+	}
+
+	data = gabs.Wrap(arrays(data.Data(), f.schema.Data(), nil, &errs))
+
+	// browsers submit nothing for unchecked checkboxes, also in the
+	// submitted items of arrays
+	for _, scope := range checkboxScopes(f.tree) {
+		if tokens, err := ScopeTokens(scope); err == nil {
+			unchecked(data, tokens, nil)
+		}
+	}
+
+	return data, errors.Join(errs...)
+}
+
+// checkboxScopes returns the scopes of the boolean controls below node,
+// including the details of arrays.
+func checkboxScopes(node Node) []string {
+	var scopes []string
+	Walk(node, func(n Node) bool {
+		switch c := n.(type) {
+		case *ArrayControl:
+			scopes = append(scopes, checkboxScopes(c.Detail)...)
+			return false
+		case *Control:
+			if c.Schema.Type == "boolean" {
+				scopes = append(scopes, c.Scope)
+			}
+		}
+		return true
+	})
+	return scopes
+}
+
+// unchecked sets the boolean at the schema scope tokens to false where data
+// has none, for every element of the arrays on the way. at is the data
+// pointer reached so far.
+func unchecked(data *gabs.Container, scope []string, at []string) {
+	for i := 0; i < len(scope); i++ {
+		switch scope[i] {
+		case "properties":
+			if i+1 < len(scope) {
+				i++
+				at = append(at[:len(at):len(at)], scope[i])
+			}
+		case "items":
+			elements, _ := data.Search(at...).Data().([]interface{})
+			for j, element := range elements {
+				if element != nil {
+					unchecked(data, scope[i+1:], append(at[:len(at):len(at)], strconv.Itoa(j)))
+				}
+			}
+			return
+		default:
+			at = append(at[:len(at):len(at)], scope[i])
+		}
+	}
+	if len(at) > 0 && !data.Exists(at...) {
+		data.Set(false, at...)
+	}
+}
+
+const (
+	// ActionField is the form field of the pressed submit button.
+	ActionField = "_action"
+	// CSRFField is the form field of the token against cross-site request
+	// forgery.
+	CSRFField = "_csrf"
+)
+
+// reserved reports the form fields of the library, like ActionField, which
+// hold no data.
+func reserved(key string) bool {
+	return strings.HasPrefix(key, "_")
+}
+
+// DecodeJSON reads a submitted JSON object into data typed by the schema.
+// Properties without a schema are left out. Objects keyed by form field
+// names, like {"/name": "John"} as htmx json-enc submits them, are decoded
+// like form values. The reserved fields, like
+// ActionField, are returned as values.
+func (f *Form) DecodeJSON(body []byte) (*gabs.Container, url.Values, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var object map[string]interface{}
+	if err := decoder.Decode(&object); err != nil || object == nil {
+		return nil, nil, &FormError{Stage: StageVerify, Err: fmt.Errorf("%w: no JSON object", ErrInvalidData)}
+	}
+
+	fields := false
+	for key := range object {
+		if strings.HasPrefix(key, "/") {
+			fields = true
+		} else if !reserved(key) {
+			fields = false
+			break
+		}
+	}
+
+	values := url.Values{}
+	for key, value := range object {
+		if !fields && !reserved(key) {
+			continue
+		}
+		switch v := value.(type) {
+		case []interface{}:
+			for _, item := range v {
+				values.Add(key, labelValue(item))
+			}
+		case nil:
+		default:
+			values.Add(key, labelValue(v))
+		}
+		delete(object, key)
+	}
+	if fields {
+		data, err := f.Decode(values)
+		return data, values, err
+	}
+	return gabs.Wrap(jsonNumbers(known(object, f.schema.Data()), f.schema.Data())), values, nil
+}
+
+// known drops the properties of decoded JSON that have no schema.
+func known(value interface{}, schema interface{}) interface{} {
+	s, _ := schema.(map[string]interface{})
+	switch v := value.(type) {
+	case []interface{}:
+		for i, item := range v {
+			v[i] = known(item, s["items"])
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			property := propertyAt(s, key)
+			if property == nil {
+				delete(v, key)
+				continue
+			}
+			v[key] = known(item, property)
+		}
+	}
+	return value
+}
+
+// jsonNumbers converts the numbers of decoded JSON to int for integer schemas
+// and float64 otherwise, like Decode types them.
+func jsonNumbers(value interface{}, schema interface{}) interface{} {
+	s, _ := schema.(map[string]interface{})
+	switch v := value.(type) {
+	case json.Number:
+		if schemaType(s) != "number" {
+			if i, err := strconv.Atoi(v.String()); err == nil {
+				return i
+			}
+		}
+		n, _ := v.Float64()
+		return n
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonNumbers(item, s["items"])
+		}
+	case map[string]interface{}:
+		properties, _ := s["properties"].(map[string]interface{})
+		for key, item := range v {
+			v[key] = jsonNumbers(item, properties[key])
+		}
+	}
+	return value
+}
+
+// schemaAt resolves the schema of the data at tokens, nil if there is none.
+func schemaAt(schema interface{}, tokens []string) map[string]interface{} {
+	current, _ := schema.(map[string]interface{})
+	for _, token := range tokens {
+		if current == nil {
+			return nil
+		}
+		if items, ok := current["items"].(map[string]interface{}); ok {
+			if _, err := strconv.Atoi(token); err == nil {
+				current = items
+				continue
+			}
+		}
+		current = propertyAt(current, token)
+	}
+	return current
+}
+
+// propertyAt returns the schema of the property name of an object schema,
+// from its properties or else its additionalProperties.
+func propertyAt(schema map[string]interface{}, name string) map[string]interface{} {
+	properties, _ := schema["properties"].(map[string]interface{})
+	if property, ok := properties[name].(map[string]interface{}); ok {
+		return property
+	}
+	additional, _ := schema["additionalProperties"].(map[string]interface{})
+	return additional
+}
+
+// coerce converts a submitted string to the type of schema.
+func coerce(schema map[string]interface{}, value string) (interface{}, error) {
+	switch schemaType(schema) {
+	case "integer":
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, errors.New("must be an integer")
+		}
+		return i, nil
+	case "number":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, errors.New("must be a number")
+		}
+		return n, nil
+	case "boolean":
+		switch value {
+		case "true", "on":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		return nil, errors.New("must be true or false")
+	case "string":
+		if schema["format"] == "date-time" {
+			return dateTime(value)
+		}
+		return value, nil
+	}
+
+	// no schema, keep numbers as before
+	if i, err := strconv.Atoi(value); err == nil {
+		return i, nil
+	}
+	return value, nil
+}
+
+// dateTime reads a date-time value as RFC 3339. Inputs of type
+// datetime-local submit it without seconds and time zone, it is read as UTC.
+func dateTime(value string) (string, error) {
+	if _, err := time.Parse(time.RFC3339, value); err == nil {
+		return value, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(time.RFC3339), nil
+		}
+	}
+	return "", errors.New("must be a date and time")
+}
+
+// arraySlack is how far the indices of submitted array items may go past
+// their number, for items removed in the browser without renumbering.
+const arraySlack = 32
+
+// arrays turns the objects that form values of arrays are set as, like
+// {"0": ..., "1": ...}, into arrays. Indices past the submitted items plus
+// arraySlack are reported as ErrInvalidData.
+func arrays(value interface{}, schema interface{}, tokens []string, errs *[]error) interface{} {
+	s, _ := schema.(map[string]interface{})
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+
+	if items, ok := s["items"]; ok {
+		n := 0
+		for key := range m {
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 {
+				return m
+			}
+			if i >= len(m)+arraySlack {
+				*errs = append(*errs, newError(StageVerify, Pointer(append(tokens[:len(tokens):len(tokens)], key)), ErrInvalidData, "index %d out of range", i))
+				return nil
+			}
+			n = max(n, i+1)
+		}
+		a := make([]interface{}, n)
+		for key, v := range m {
+			i, _ := strconv.Atoi(key)
+			a[i] = arrays(v, items, append(tokens[:len(tokens):len(tokens)], key), errs)
+		}
+		return a
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	for key, v := range m {
+		m[key] = arrays(v, properties[key], append(tokens[:len(tokens):len(tokens)], key), errs)
+	}
+	return m
+}
+
+// Validate checks data against the schema. It supports type, required, enum,
+// const, minLength, maxLength, pattern, format (date, date-time, email), minimum,
+// maximum, exclusiveMinimum, exclusiveMaximum, minItems and maxItems.
+// Every finding is a *FormError wrapping a ValidationError.
+func (f *Form) Validate(data *gabs.Container) error {
+	var errs []error
+	schema, _ := f.schema.Data().(map[string]interface{})
+	validate(schema, data.Data(), nil, true, &errs)
+	return errors.Join(errs...)
+}
+
+// ValidateDraft checks data like Validate, but allows required values to be
+// missing, e.g. to save a draft.
+func (f *Form) ValidateDraft(data *gabs.Container) error {
+	var errs []error
+	schema, _ := f.schema.Data().(map[string]interface{})
+	validate(schema, data.Data(), nil, false, &errs)
+	return errors.Join(errs...)
+}
+
+func validate(schema map[string]interface{}, value interface{}, tokens []string, required bool, errs *[]error) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, invalid(tokens, format, args...))
+	}
+
+	if expected, ok := schema["const"]; ok && labelValue(expected) != labelValue(value) {
+		fail("must be %s", labelValue(expected))
+		return
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, option := range enum {
+			found = found || labelValue(option) == labelValue(value)
+		}
+		if !found {
+			options := make([]string, len(enum))
+			for i, option := range enum {
+				options[i] = labelValue(option)
+			}
+			fail("must be one of %s", strings.Join(options, ", "))
+			return
+		}
+	}
+
+	typ := schemaType(schema)
+	switch typ {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		validateString(schema, s, fail)
+	case "integer", "number":
+		n, ok := number(value)
+		if !ok {
+			fail("must be a number")
+			return
+		}
+		if typ == "integer" && n != math.Trunc(n) {
+			fail("must be an integer")
+			return
+		}
+		validateNumber(schema, n, fail)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be true or false")
+		}
+	case "array", "array-select":
+		a, ok := value.([]interface{})
+		if !ok {
+			fail("must be a list")
+			return
+		}
+		if limit, ok := number(schema["minItems"]); ok && float64(len(a)) < limit {
+			fail("must have at least %v items", limit)
+		}
+		if limit, ok := number(schema["maxItems"]); ok && float64(len(a)) > limit {
+			fail("must have at most %v items", limit)
+		}
+		items, _ := schema["items"].(map[string]interface{})
+		for i, item := range a {
+			if item != nil {
+				validate(items, item, append(tokens[:len(tokens):len(tokens)], strconv.Itoa(i)), required, errs)
+			}
+		}
+	default:
+		// objects, also without type
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			if typ == "object" {
+				fail("must be an object")
+			}
+			return
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for _, name := range requiredNames(schema) {
+			if required && m[name] == nil {
+				*errs = append(*errs, invalid(append(tokens[:len(tokens):len(tokens)], name), "is required"))
+			}
+		}
+
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := properties[name].(map[string]interface{}); ok && m[name] != nil {
+				validate(property, m[name], append(tokens[:len(tokens):len(tokens)], name), required, errs)
+			}
+		}
+	}
+}
+
+func validateString(schema map[string]interface{}, s string, fail func(string, ...interface{})) {
+	length := float64(utf8.RuneCountInString(s))
+	if limit, ok := number(schema["minLength"]); ok && length < limit {
+		fail("must be at least %v characters", limit)
+	}
+	if limit, ok := number(schema["maxLength"]); ok && length > limit {
+		fail("must be at most %v characters", limit)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(s) {
+			fail("must match %s", pattern)
+		}
+	}
+	switch schema["format"] {
+	case "date":
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			fail("must be a date")
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			fail("must be a date and time")
+		}
+	case "email":
+		if _, err := mail.ParseAddress(s); err != nil {
+			fail("must be an email address")
+		}
+	}
+}
+
+func validateNumber(schema map[string]interface{}, n float64, fail func(string, ...interface{})) {
+	if limit, ok := number(schema["minimum"]); ok && n < limit {
+		fail("must be at least %v", limit)
+	}
+	if limit, ok := number(schema["maximum"]); ok && n > limit {
+		fail("must be at most %v", limit)
+	}
+	if limit, ok := number(schema["exclusiveMinimum"]); ok && n <= limit {
+		fail("must be greater than %v", limit)
+	}
+	if limit, ok := number(schema["exclusiveMaximum"]); ok && n >= limit {
+		fail("must be less than %v", limit)
+	}
+}
+
+// number reads any JSON or Go number.
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func requiredNames(schema map[string]interface{}) []string {
+	var names []string
+	switch required := schema["required"].(type) {
+	case []interface{}:
+		for _, name := range required {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+	case []string:
+		names = required
+	}
+	return names
+}
+
+// FieldErrors collects the messages of the *FormError values in err by their
+// scope, as they are shown next to the controls.
+func FieldErrors(err error) map[string][]string {
+	fields := map[string][]string{}
+	var collect func(error)
+	collect = func(err error) {
+		switch e := err.(type) {
+		case nil:
+		case *FormError:
+			if e.Scope == "" {
+				return
+			}
+			var v *ValidationError
+			if errors.As(e.Err, &v) {
+				fields[e.Scope] = append(fields[e.Scope], v.Message)
+			} else {
+				fields[e.Scope] = append(fields[e.Scope], e.Err.Error())
+			}
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				collect(err)
+			}
+		default:
+			collect(errors.Unwrap(err))
+		}
+	}
+	collect(err)
+	return fields
+}
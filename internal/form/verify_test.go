@@ -0,0 +1,151 @@
+package form_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	gabs "github.com/Jeffail/gabs/v2"
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+)
+
+func TestDecodeAndValidate(t *testing.T) {
+	schema, _ := gabs.ParseJSON([]byte(`{
+		"type": "object",
+		"required": ["name", "email"],
+		"properties": {
+			"name": {"type": "string", "maxLength": 5},
+			"email": {"type": "string", "format": "email"},
+			"height": {"type": "number", "exclusiveMinimum": 0},
+			"country": {"enum": ["DE", "IT"]},
+			"active": {"type": "boolean"},
+			"comments": {
+				"type": "array",
+				"items": {
+					"type": "object",
+					"required": ["message"],
+					"properties": {"message": {"type": "string"}, "stars": {"type": "integer"}}
+				}
+			}
+		}
+	}`))
+	uischema, _ := gabs.ParseJSON([]byte(`{
+		"type": "VerticalLayout",
+		"elements": [
+			{"type": "Control", "scope": "#/properties/name"},
+			{"type": "Control", "scope": "#/properties/active"},
+			{"type": "Control", "scope": "#/properties/comments"}
+		]
+	}`))
+	f, err := form.NewForm(schema, uischema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := f.Decode(url.Values{
+		"/name":               {"Johnny"},
+		"/email":              {""},
+		"/height":             {"1.85"},
+		"/country":            {"FR"},
+		"/comments/0/stars":   {"5"},
+		"/comments/1/message": {"Hi"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"name":    "Johnny",
+		"height":  1.85,
+		"country": "FR",
+		"active":  false,
+		"comments": []interface{}{
+			map[string]interface{}{"stars": 5},
+			map[string]interface{}{"message": "Hi"},
+		},
+	}
+	if !reflect.DeepEqual(data.Data(), expected) {
+		t.Errorf("unexpected data %s", data.String())
+	}
+
+	err = f.Validate(data)
+	if !errors.Is(err, form.ErrValidation) {
+		t.Fatalf("expected ErrValidation, got %v", err)
+	}
+	fields := form.FieldErrors(err)
+	expectedFields := map[string][]string{
+		"/name":               {"must be at most 5 characters"},
+		"/email":              {"is required"},
+		"/country":            {"must be one of DE, IT"},
+		"/comments/0/message": {"is required"},
+	}
+	if !reflect.DeepEqual(fields, expectedFields) {
+		t.Errorf("unexpected errors %v", fields)
+	}
+
+	// values without a schema are dropped
+	data, err = f.Decode(url.Values{"/name": {"Jo"}, "/isAdmin": {"true"}, "/comments/0/admin": {"true"}})
+	if err != nil || !reflect.DeepEqual(data.Data(), map[string]interface{}{"name": "Jo", "active": false}) {
+		t.Errorf("unexpected data %s, %v", data.String(), err)
+	}
+	data, _, err = f.DecodeJSON([]byte(`{"name": "Jo", "isAdmin": true, "comments": [{"message": "Hi", "admin": true}]}`))
+	if err != nil || !reflect.DeepEqual(data.Data(), map[string]interface{}{"name": "Jo", "comments": []interface{}{map[string]interface{}{"message": "Hi"}}}) {
+		t.Errorf("unexpected data %s, %v", data.String(), err)
+	}
+
+	// indices far past the submitted items are rejected, not allocated
+	for _, key := range []string{"/comments/4611686018427387904/message", "/comments/100000000/message"} {
+		if _, err := f.Decode(url.Values{key: {"Hi"}}); !errors.Is(err, form.ErrInvalidData) {
+			t.Errorf("expected ErrInvalidData for %s, got %v", key, err)
+		}
+	}
+	data, err = f.Decode(url.Values{"/comments/2/message": {"Hi"}})
+	if comments, _ := data.Path("comments").Data().([]interface{}); err != nil || len(comments) != 3 {
+		t.Errorf("expected gaps of removed items, got %s, %v", data.String(), err)
+	}
+
+	// values of the wrong type are reported at their pointer
+	_, err = f.Decode(url.Values{"/height": {"tall"}})
+	if fields := form.FieldErrors(err); !reflect.DeepEqual(fields, map[string][]string{"/height": {"must be a number"}}) {
+		t.Errorf("unexpected errors %v", fields)
+	}
+}
+
+func TestDecodeCheckboxesInArrays(t *testing.T) {
+	schema, _ := gabs.ParseJSON([]byte(`{
+		"properties": {
+			"active": {"type": "boolean"},
+			"tasks": {
+				"type": "array",
+				"items": {"type": "object", "properties": {"title": {"type": "string"}, "done": {"type": "boolean"}}}
+			}
+		}
+	}`))
+	uischema, _ := gabs.ParseJSON([]byte(`{
+		"type": "VerticalLayout",
+		"elements": [
+			{"type": "Control", "scope": "#/properties/active"},
+			{"type": "Control", "scope": "#/properties/tasks"}
+		]
+	}`))
+	f, err := form.NewForm(schema, uischema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := f.Decode(url.Values{"/tasks/0/title": {"a"}, "/tasks/1/title": {"b"}, "/tasks/1/done": {"true"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"active": false,
+		"tasks": []interface{}{
+			map[string]interface{}{"title": "a", "done": false},
+			map[string]interface{}{"title": "b", "done": true},
+		},
+	}
+	if !reflect.DeepEqual(data.Data(), expected) {
+		t.Errorf("unexpected data %s", data.String())
+	}
+}
@@ -0,0 +1,462 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+)
+
+// GoOptions are the settings of GenerateGo.
+type GoOptions struct {
+	// Package is the package of the generated file, "main" if empty
+	Package string
+	// Type names the type of the schema, else its title, else "Form"
+	Type string
+	// Source is the schema file named in the header of the generated file
+	Source string
+}
+
+// GenerateGo generates Go types for a JSON schema, to decode verified data
+// into. Objects become structs with fields in schema order, nested objects
+// become types named after their parent and field, string enums become typed
+// string constants and $defs (or definitions) become named types. Fields not
+// required or nullable are pointers. The result is gofmt-ed.
+func GenerateGo(schema []byte, opts GoOptions) ([]byte, error) {
+	root, err := decodeOrdered(schema)
+	if err != nil {
+		return nil, err
+	}
+	rootObj, ok := root.(*object)
+	if !ok {
+		return nil, errors.New("schema is no object")
+	}
+	if opts.Package == "" {
+		opts.Package = "main"
+	}
+	name := opts.Type
+	if name == "" {
+		title, _ := rootObj.get("title").(string)
+		name = identifier(goName(title), "Form")
+	}
+
+	g := &goGenerator{
+		refs:    map[string]string{"#": name},
+		defs:    map[string]*object{"#": rootObj},
+		names:   map[string]bool{name: true},
+		imports: map[string]bool{},
+	}
+
+	// defs first, so refs to them resolve everywhere, also recursively
+	var defs []string
+	for _, key := range []string{"$defs", "definitions"} {
+		d, _ := rootObj.get(key).(*object)
+		if d == nil {
+			continue
+		}
+		for _, defName := range d.keys {
+			ref := "#/" + key + "/" + form.EscapeToken(defName)
+			g.refs[ref] = g.name(goName(defName), "Def")
+			g.defs[ref], _ = d.get(defName).(*object)
+			defs = append(defs, ref)
+		}
+	}
+
+	if err := g.named(rootObj, name, "#"); err != nil {
+		return nil, err
+	}
+	for _, ref := range defs {
+		if err := g.named(g.defs[ref], g.refs[ref], ref); err != nil {
+			return nil, err
+		}
+	}
+
+	var src bytes.Buffer
+	source := ""
+	if opts.Source != "" {
+		source = " from " + opts.Source
+	}
+	fmt.Fprintf(&src, "// Code generated by jsonforms-gen%s. DO NOT EDIT.\n\npackage %s\n\n", source, opts.Package)
+	if g.imports["time"] {
+		src.WriteString("import \"time\"\n\n")
+	}
+	for _, decl := range g.decls {
+		src.WriteString(decl)
+		src.WriteString("\n")
+	}
+
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid Go: %w", err)
+	}
+	return out, nil
+}
+
+type goGenerator struct {
+	// refs are the type names of the $defs by pointer, defs their schemas
+	refs map[string]string
+	defs map[string]*object
+	// reserved is the name of the type being declared by named
+	reserved string
+	// names are the identifiers declared in the file
+	names   map[string]bool
+	imports map[string]bool
+	// decls are the type and const declarations in order
+	decls []string
+}
+
+// name reserves an unused identifier, base itself or with suffix.
+func (g *goGenerator) name(base, suffix string) string {
+	base = identifier(base, suffix)
+	name := base
+	for i := 2; g.names[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	g.names[name] = true
+	return name
+}
+
+// named declares the type of s as name, the root schema or a def.
+func (g *goGenerator) named(s *object, name, pointer string) error {
+	g.reserved = name
+	typ, _, err := g.goType(s, name, pointer)
+	g.reserved = ""
+	if err != nil {
+		return err
+	}
+	if typ != name {
+		// schemas without struct or enum are defined types of their Go type
+		g.decls = append(g.decls, doc(s)+"type "+name+" "+typ+"\n")
+	}
+	return nil
+}
+
+// declare reserves the name of a declared struct or enum type.
+func (g *goGenerator) declare(name, suffix string) string {
+	if name == g.reserved {
+		g.reserved = ""
+		return name
+	}
+	return g.name(name, suffix)
+}
+
+// goType returns the Go type of s and whether it is nil-able by itself. name
+// is used for the types declared for s.
+func (g *goGenerator) goType(s *object, name, pointer string) (string, bool, error) {
+	if s == nil {
+		return "interface{}", true, nil
+	}
+	if ref, ok := s.get("$ref").(string); ok {
+		typ, ok := g.refs[ref]
+		if !ok {
+			return "", false, fmt.Errorf("%s: unresolved $ref %q", pointer, ref)
+		}
+		return typ, nilable(g.defs[ref]), nil
+	}
+
+	typ, _ := types(s)
+	switch typ {
+	case "string":
+		if enum := stringEnum(s); len(enum) > 0 {
+			return g.enum(s, name, enum), false, nil
+		}
+		if s.get("format") == "date-time" {
+			g.imports["time"] = true
+			return "time.Time", false, nil
+		}
+		if s.get("contentEncoding") == "base64" {
+			return "[]byte", true, nil
+		}
+		return "string", false, nil
+	case "integer":
+		return "int", false, nil
+	case "number":
+		return "float64", false, nil
+	case "boolean":
+		return "bool", false, nil
+	case "array":
+		items, _ := s.get("items").(*object)
+		elem, _, err := g.goType(items, name+"Item", pointer+"/items")
+		if err != nil {
+			return "", false, err
+		}
+		return "[]" + elem, true, nil
+	case "object":
+		if properties, ok := s.get("properties").(*object); ok && len(properties.keys) > 0 {
+			return g.object(s, properties, name, pointer)
+		}
+		if values, ok := s.get("additionalProperties").(*object); ok {
+			elem, _, err := g.goType(values, name+"Value", pointer+"/additionalProperties")
+			if err != nil {
+				return "", false, err
+			}
+			return "map[string]" + elem, true, nil
+		}
+		return "map[string]interface{}", true, nil
+	}
+	return "interface{}", true, nil
+}
+
+// object declares the struct of an object schema.
+func (g *goGenerator) object(s, properties *object, name, pointer string) (string, bool, error) {
+	name = g.declare(name, "Object")
+	required := map[string]bool{}
+	if list, ok := s.get("required").([]interface{}); ok {
+		for _, r := range list {
+			if r, ok := r.(string); ok {
+				required[r] = true
+			}
+		}
+	}
+
+	// declared before the types of its fields
+	i := len(g.decls)
+	g.decls = append(g.decls, "")
+
+	var body strings.Builder
+	fields := map[string]bool{}
+	for _, key := range properties.keys {
+		property, _ := properties.get(key).(*object)
+		field := identifier(goName(key), "Field")
+		for base, n := field, 2; fields[field]; n++ {
+			field = base + strconv.Itoa(n)
+		}
+		fields[field] = true
+
+		typ, nilable, err := g.goType(property, name+field, pointer+"/properties/"+form.EscapeToken(key))
+		if err != nil {
+			return "", false, err
+		}
+		tag := key
+		if !required[key] {
+			tag += ",omitempty"
+		}
+		if !nilable && (!required[key] || nullable(property)) {
+			typ = "*" + typ
+		}
+		body.WriteString(indent(doc(property)))
+		fmt.Fprintf(&body, "\t%s %s `json:%q`\n", field, typ, tag)
+	}
+	g.decls[i] = doc(s) + "type " + name + " struct {\n" + body.String() + "}\n"
+	return name, false, nil
+}
+
+// enum declares a string type with a constant per value.
+func (g *goGenerator) enum(s *object, name string, values []string) string {
+	name = g.declare(name, "Enum")
+	var decl strings.Builder
+	decl.WriteString(doc(s) + "type " + name + " string\n\nconst (\n")
+	for _, value := range values {
+		suffix := goName(value)
+		if suffix == "" {
+			suffix = "Empty"
+		}
+		fmt.Fprintf(&decl, "\t%s %s = %q\n", g.name(name+suffix, ""), name, value)
+	}
+	decl.WriteString(")\n")
+	g.decls = append(g.decls, decl.String())
+	return name
+}
+
+// doc turns the description, else the title, of s into a comment.
+func doc(s *object) string {
+	if s == nil {
+		return ""
+	}
+	text, _ := s.get("description").(string)
+	if text == "" {
+		text, _ = s.get("title").(string)
+	}
+	if text == "" {
+		return ""
+	}
+	return "// " + strings.ReplaceAll(strings.TrimSpace(text), "\n", "\n// ") + "\n"
+}
+
+func indent(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "\t" + strings.ReplaceAll(strings.TrimSuffix(s, "\n"), "\n", "\n\t") + "\n"
+}
+
+// types returns the type of s, the first besides "null", and whether it is
+// nullable. Schemas with properties are objects, with items arrays and with
+// string enums strings.
+func types(s *object) (typ string, null bool) {
+	switch t := s.get("type").(type) {
+	case string:
+		typ, null = t, t == "null"
+	case []interface{}:
+		for _, v := range t {
+			if v == "null" {
+				null = true
+			} else if v, ok := v.(string); ok && typ == "" {
+				typ = v
+			}
+		}
+	}
+	if typ == "" {
+		switch {
+		case s.get("properties") != nil:
+			typ = "object"
+		case s.get("items") != nil:
+			typ = "array"
+		case len(stringEnum(s)) > 0:
+			typ = "string"
+		}
+	}
+	return typ, null
+}
+
+// nilable reports whether the Go type of s can be nil by itself.
+func nilable(s *object) bool {
+	if s == nil {
+		return true
+	}
+	if s.get("$ref") != nil {
+		return false
+	}
+	switch typ, _ := types(s); typ {
+	case "array":
+		return true
+	case "object":
+		properties, _ := s.get("properties").(*object)
+		return properties == nil || len(properties.keys) == 0
+	case "string":
+		return s.get("contentEncoding") == "base64" && len(stringEnum(s)) == 0
+	case "integer", "number", "boolean":
+		return false
+	}
+	return true
+}
+
+func nullable(s *object) bool {
+	if s == nil {
+		return false
+	}
+	_, null := types(s)
+	return null
+}
+
+// stringEnum returns the enum of s if all its values are strings or null.
+func stringEnum(s *object) []string {
+	enum, _ := s.get("enum").([]interface{})
+	var values []string
+	for _, v := range enum {
+		switch v := v.(type) {
+		case string:
+			values = append(values, v)
+		case nil:
+		default:
+			return nil
+		}
+	}
+	return values
+}
+
+// initialisms are written in upper case in Go names.
+var initialisms = map[string]bool{
+	"API": true, "CSS": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IP": true, "JSON": true, "SQL": true, "UI": true, "URI": true, "URL": true, "UUID": true,
+}
+
+// identifier prefixes name, if it doesn't start with a letter.
+func identifier(name, prefix string) string {
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		return prefix + name
+	}
+	return name
+}
+
+// goName turns a property name like "first_name" or "user-id" into an
+// exported Go name like FirstName or UserID.
+func goName(s string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(word)
+		b.WriteString(string(unicode.ToUpper(r[0])) + string(r[1:]))
+	}
+	return b.String()
+}
+
+// object is a JSON object that keeps the order of its keys, so fields follow
+// the order of the schema file.
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (o *object) get(key string) interface{} {
+	if o == nil {
+		return nil
+	}
+	return o.values[key]
+}
+
+// decodeOrdered decodes JSON with objects as *object.
+func decodeOrdered(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, err := decodeValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid JSON after the schema")
+	}
+	return v, nil
+}
+
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		o := &object{values: map[string]interface{}{}}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			k := key.(string)
+			if _, ok := o.values[k]; !ok {
+				o.keys = append(o.keys, k)
+			}
+			o.values[k] = v
+		}
+		_, err := dec.Token()
+		return o, err
+	case json.Delim('['):
+		a := []interface{}{}
+		for dec.More() {
+			v, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+		_, err := dec.Token()
+		return a, err
+	}
+	return tok, nil
+}
@@ -0,0 +1,92 @@
+package schema
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGo(t *testing.T) {
+	schema, err := os.ReadFile("testdata/settings.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := os.ReadFile("testdata/settings.go.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := GenerateGo(schema, GoOptions{Package: "settings", Source: "settings.schema.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != string(expected) {
+		t.Errorf("generated\n%s\nexpected\n%s", out, expected)
+	}
+}
+
+func TestGenerateGoNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		opts     GoOptions
+		contains []string
+	}{
+		{
+			name:     "type option",
+			schema:   `{"title": "Ignored", "properties": {"a": {"type": "string"}}}`,
+			opts:     GoOptions{Type: "Settings"},
+			contains: []string{"package main", "type Settings struct", "A *string"},
+		},
+		{
+			name:     "default name",
+			schema:   `{"properties": {"1st": {"type": "string"}, "first": {"type": "string"}, "First": {"type": "string"}}}`,
+			contains: []string{"type Form struct", "Field1st *string", "First    *string", "First2   *string"},
+		},
+		{
+			name:     "colliding type names",
+			schema:   `{"properties": {"address": {"properties": {"a": {"type": "string"}}}}, "$defs": {"formAddress": {"type": "string"}}}`,
+			contains: []string{"Address *FormAddress2", "type FormAddress2 struct", "type FormAddress string"},
+		},
+		{
+			name:     "definitions and self reference",
+			schema:   `{"title": "node", "properties": {"children": {"type": "array", "items": {"$ref": "#"}}, "tags": {"$ref": "#/definitions/tags"}}, "definitions": {"tags": {"type": "array", "items": {"type": "string"}}}}`,
+			contains: []string{"Children []Node", "Tags     Tags", "type Tags []string"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := GenerateGo([]byte(tt.schema), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range tt.contains {
+				if !strings.Contains(string(out), c) {
+					t.Errorf("expected %q in\n%s", c, out)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateGoErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		err    string
+	}{
+		{"invalid json", `{"type": `, "EOF"},
+		{"no object", `[]`, "schema is no object"},
+		{"unresolved ref", `{"properties": {"a": {"$ref": "#/$defs/missing"}}}`, `#/properties/a: unresolved $ref "#/$defs/missing"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateGo([]byte(tt.schema), GoOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+// Package schema generates JSON schemas and UI schemas from Go types.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+)
+
+// Tag is the struct tag read besides json, a comma separated list of
+//
+//	title=..., description=..., format=..., pattern=...,
+//	enum=a|b (or enum=a,enum=b), default=..., min=..., max=...,
+//	readonly, required
+//
+// min and max limit the value of numbers, the length of strings and the
+// number of items of lists. Values can't contain commas.
+const Tag = "jsonschema"
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// Generate builds the JSON schema of the struct type t and a starter UI
+// schema: a vertical layout with a control per field in field order and a
+// group per nested struct. Maps and interfaces get no control. Exported fields are named by their json tag,
+// fields without omitempty are required, pointers are nullable and
+// time.Time is a date-time string.
+func Generate(t reflect.Type) (schema, uiSchema map[string]interface{}, err error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("%s is no struct", t)
+	}
+
+	g := &generator{seen: map[reflect.Type]bool{}}
+	schema, elements, err := g.object(t, "#")
+	if err != nil {
+		return nil, nil, err
+	}
+	return schema, map[string]interface{}{"type": "VerticalLayout", "elements": elements}, nil
+}
+
+type generator struct {
+	// seen are the structs being generated, to stop at recursive types
+	seen map[reflect.Type]bool
+}
+
+// object generates the schema of a struct and the UI elements of its fields.
+func (g *generator) object(t reflect.Type, scope string) (map[string]interface{}, []interface{}, error) {
+	if g.seen[t] {
+		return nil, nil, fmt.Errorf("%s: recursive types are not supported", t)
+	}
+	g.seen[t] = true
+	defer delete(g.seen, t)
+
+	properties := map[string]interface{}{}
+	required := []interface{}{}
+	elements := []interface{}{}
+	if err := g.fields(t, scope, properties, &required, &elements); err != nil {
+		return nil, nil, err
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, elements, nil
+}
+
+// fields adds the fields of t, and of its embedded structs, to an object.
+func (g *generator) fields(t reflect.Type, scope string, properties map[string]interface{}, required *[]interface{}, elements *[]interface{}) error {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, omitempty, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+
+		// embedded structs without name are part of the object, like json does
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := g.fields(embedded, scope, properties, required, elements); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := properties[name]; ok {
+			continue
+		}
+
+		propertyScope := scope + "/properties/" + form.EscapeToken(name)
+		property, nested, err := g.value(field.Type, propertyScope)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t, field.Name, err)
+		}
+		force, err := applyTag(property, field.Tag.Get(Tag))
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", t, field.Name, err)
+		}
+
+		properties[name] = property
+		if !omitempty || force {
+			*required = append(*required, name)
+		}
+		switch {
+		case nested != nil:
+			label := name
+			if title, ok := property["title"].(string); ok {
+				label = title
+			}
+			*elements = append(*elements, map[string]interface{}{"type": "Group", "label": label, "elements": nested})
+		case property["additionalProperties"] != nil, len(property) == 0:
+			// maps and values of any type have no control
+		default:
+			*elements = append(*elements, map[string]interface{}{"type": "Control", "scope": propertyScope})
+		}
+	}
+	return nil
+}
+
+// value generates the schema of a Go type, and the UI elements if it is a
+// struct.
+func (g *generator) value(t reflect.Type, scope string) (map[string]interface{}, []interface{}, error) {
+	switch {
+	case t.Kind() == reflect.Pointer:
+		schema, elements, err := g.value(t.Elem(), scope)
+		if typ, ok := schema["type"].(string); ok {
+			schema["type"] = []interface{}{typ, "null"}
+		}
+		return schema, elements, err
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil, nil
+	case t.Implements(marshalerType):
+		// marshals itself, to any value
+		return map[string]interface{}{}, nil, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return g.object(t, scope)
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}, nil, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "minimum": 0}, nil, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}, nil, nil
+		}
+		items, _, err := g.value(t.Elem(), scope+"/items")
+		if err != nil {
+			return nil, nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, nil, fmt.Errorf("%s: map keys must be strings", t)
+		}
+		values, _, err := g.value(t.Elem(), scope+"/additionalProperties")
+		if err != nil {
+			return nil, nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil, nil
+	}
+	return nil, nil, fmt.Errorf("%s is not supported", t)
+}
+
+// jsonName reads the json tag of a field, false if the field is skipped.
+func jsonName(field reflect.StructField) (name string, omitempty bool, ok bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	for _, option := range strings.Split(options, ",") {
+		omitempty = omitempty || option == "omitempty" || option == "omitzero"
+	}
+	return name, omitempty, true
+}
+
+// applyTag adds the keywords of a jsonschema tag to schema and reports
+// whether the tag makes the field required.
+func applyTag(schema map[string]interface{}, tag string) (required bool, err error) {
+	if tag == "" {
+		return false, nil
+	}
+	typ := ""
+	switch t := schema["type"].(type) {
+	case string:
+		typ = t
+	case []interface{}:
+		typ, _ = t[0].(string)
+	}
+
+	for _, part := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "title", "description", "format", "pattern":
+			schema[key] = value
+		case "readonly":
+			schema["readOnly"] = true
+		case "required":
+			required = true
+		case "enum":
+			enum, _ := schema["enum"].([]interface{})
+			for _, option := range strings.Split(value, "|") {
+				v, err := parse(typ, option)
+				if err != nil {
+					return false, fmt.Errorf("enum: %w", err)
+				}
+				enum = append(enum, v)
+			}
+			schema["enum"] = enum
+		case "default":
+			v, err := parse(typ, value)
+			if err != nil {
+				return false, fmt.Errorf("default: %w", err)
+			}
+			schema["default"] = v
+		case "min", "max":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return false, fmt.Errorf("%s: %w", key, err)
+			}
+			keyword, ok := limits[typ][key]
+			if !ok {
+				return false, fmt.Errorf("%s is not supported for %s", key, typ)
+			}
+			schema[keyword] = n
+		default:
+			return false, fmt.Errorf("unknown %s tag %q", Tag, key)
+		}
+	}
+	return required, nil
+}
+
+// limits are the keywords of min and max by type.
+var limits = map[string]map[string]string{
+	"integer": {"min": "minimum", "max": "maximum"},
+	"number":  {"min": "minimum", "max": "maximum"},
+	"string":  {"min": "minLength", "max": "maxLength"},
+	"array":   {"min": "minItems", "max": "maxItems"},
+}
+
+// parse reads a tag value as a value of the schema type.
+func parse(typ, value string) (interface{}, error) {
+	switch typ {
+	case "integer":
+		return strconv.Atoi(value)
+	case "number":
+		return strconv.ParseFloat(value, 64)
+	case "boolean":
+		return strconv.ParseBool(value)
+	}
+	return value, nil
+}
@@ -0,0 +1,152 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type address struct {
+	Street string `json:"street" jsonschema:"title=Street,min=3"`
+	Zip    string `json:"zip,omitempty" jsonschema:"pattern=^[0-9]{5}$"`
+}
+
+type Audit struct {
+	Created time.Time `json:"created"`
+}
+
+type user struct {
+	Audit
+	Name     string            `json:"name" jsonschema:"title=Name,description=Your full name"`
+	Role     string            `json:"role,omitempty" jsonschema:"enum=admin|user,default=user"`
+	Age      *int              `json:"age,omitempty" jsonschema:"min=0,max=150"`
+	Active   bool              `json:"active"`
+	Score    float64           `json:"score,omitempty" jsonschema:"readonly"`
+	Count    uint              `json:"count,omitempty"`
+	Tags     []string          `json:"tags,omitempty" jsonschema:"max=5"`
+	Address  address           `json:"address"`
+	Previous []address         `json:"previous,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Avatar   []byte            `json:"avatar,omitempty"`
+	Extra    interface{}       `json:"extra,omitempty"`
+	Note     string            `json:",omitempty" jsonschema:"required"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+func TestGenerate(t *testing.T) {
+	schema, uiSchema, err := Generate(reflect.TypeOf(&user{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedSchema := `{
+		"type": "object",
+		"required": ["created", "name", "active", "address", "Note"],
+		"properties": {
+			"created": {"type": "string", "format": "date-time"},
+			"name": {"type": "string", "title": "Name", "description": "Your full name"},
+			"role": {"type": "string", "enum": ["admin", "user"], "default": "user"},
+			"age": {"type": ["integer", "null"], "minimum": 0, "maximum": 150},
+			"active": {"type": "boolean"},
+			"score": {"type": "number", "readOnly": true},
+			"count": {"type": "integer", "minimum": 0},
+			"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 5},
+			"address": {
+				"type": "object",
+				"required": ["street"],
+				"properties": {
+					"street": {"type": "string", "title": "Street", "minLength": 3},
+					"zip": {"type": "string", "pattern": "^[0-9]{5}$"}
+				}
+			},
+			"previous": {"type": "array", "items": {
+				"type": "object",
+				"required": ["street"],
+				"properties": {
+					"street": {"type": "string", "title": "Street", "minLength": 3},
+					"zip": {"type": "string", "pattern": "^[0-9]{5}$"}
+				}
+			}},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"avatar": {"type": "string", "contentEncoding": "base64"},
+			"extra": {},
+			"Note": {"type": "string"}
+		}
+	}`
+	expectedUISchema := `{
+		"type": "VerticalLayout",
+		"elements": [
+			{"type": "Control", "scope": "#/properties/created"},
+			{"type": "Control", "scope": "#/properties/name"},
+			{"type": "Control", "scope": "#/properties/role"},
+			{"type": "Control", "scope": "#/properties/age"},
+			{"type": "Control", "scope": "#/properties/active"},
+			{"type": "Control", "scope": "#/properties/score"},
+			{"type": "Control", "scope": "#/properties/count"},
+			{"type": "Control", "scope": "#/properties/tags"},
+			{"type": "Group", "label": "address", "elements": [
+				{"type": "Control", "scope": "#/properties/address/properties/street"},
+				{"type": "Control", "scope": "#/properties/address/properties/zip"}
+			]},
+			{"type": "Control", "scope": "#/properties/previous"},
+			{"type": "Control", "scope": "#/properties/avatar"},
+			{"type": "Control", "scope": "#/properties/Note"}
+		]
+	}`
+
+	for _, test := range []struct {
+		actual   map[string]interface{}
+		expected string
+	}{{schema, expectedSchema}, {uiSchema, expectedUISchema}} {
+		var expected interface{}
+		if err := json.Unmarshal([]byte(test.expected), &expected); err != nil {
+			t.Fatal(err)
+		}
+		// compare as JSON, the schema holds ints and []interface{}
+		raw, _ := json.Marshal(test.actual)
+		var actual interface{}
+		json.Unmarshal(raw, &actual)
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("unexpected schema:\n%s", raw)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	type recursive struct {
+		Children []recursive `json:"children"`
+	}
+	type unknownTag struct {
+		Name string `jsonschema:"titel=Name"`
+	}
+	type badEnum struct {
+		Level int `jsonschema:"enum=low|high"`
+	}
+	type badLimit struct {
+		Active bool `jsonschema:"min=1"`
+	}
+	type channel struct {
+		C chan int
+	}
+
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{0, "is no struct"},
+		{recursive{}, "recursive types are not supported"},
+		{unknownTag{}, `unknown jsonschema tag "titel"`},
+		{badEnum{}, "enum:"},
+		{badLimit{}, "min is not supported for boolean"},
+		{channel{}, "chan int is not supported"},
+	}
+	for _, test := range tests {
+		_, _, err := Generate(reflect.TypeOf(test.value))
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("expected error %q for %T, got %v", test.expected, test.value, err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package gojsonforms
+
+import (
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+)
+
+var defaultLogger atomic.Pointer[slog.Logger]
+
+// SetDefaultLogger sets the logger used by every builder without WithLogger.
+// The library logs debug events only and is silent unless a logger is set.
+// A nil logger restores the silent default.
+func SetDefaultLogger(logger *slog.Logger) {
+	defaultLogger.Store(logger)
+}
+
+// logger returns the builder's logger, the package default or a discarding one.
+func (b *builder) logger() *slog.Logger {
+	if b.log != nil {
+		return b.log
+	}
+	if logger := defaultLogger.Load(); logger != nil {
+		return logger
+	}
+	return form.DiscardLogger
+}
@@ -10,7 +10,26 @@ type MenuItem struct {
 type Confirmation struct {
 	ButtonText string
 	Title      string
-	Body       string
-	Confirm    string
-	Cancel     string
+	// Body is text, data pointers in braces like {/name} are replaced by the
+	// values about to be submitted
+	Body    string
+	Confirm string
+	Cancel  string
+	// Summary lists all values about to be submitted below the body
+	Summary bool
+}
+
+// Action is a submit button of a form. The name of the pressed one is
+// submitted as _action.
+type Action struct {
+	Name  string
+	Label string
+	// Style is the button style, e.g. "primary", "link" or "error"
+	Style string
+	// Target replaces the post link of the form
+	Target string
+	// Confirmation, if set, is asked before submitting
+	Confirmation *Confirmation
+	// Draft saves skip the checks of required values
+	Draft bool
 }
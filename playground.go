@@ -0,0 +1,195 @@
+package gojsonforms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/TobiEiss/go-jsonforms/internal/form"
+)
+
+// playgroundExample is the form the playground starts with.
+var playgroundExample = struct{ schema, uiSchema, data string }{
+	schema: `{
+  "type": "object",
+  "required": ["name", "email"],
+  "properties": {
+    "name": {"type": "string", "title": "Name", "minLength": 3},
+    "email": {"type": "string", "title": "Email", "format": "email"},
+    "role": {"type": "string", "title": "Role", "enum": ["admin", "editor", "viewer"]},
+    "newsletter": {"type": "boolean", "title": "Newsletter"}
+  }
+}
+`,
+	uiSchema: `{
+  "type": "VerticalLayout",
+  "elements": [
+    {"type": "Control", "scope": "#/properties/name"},
+    {"type": "Control", "scope": "#/properties/email"},
+    {"type": "Control", "scope": "#/properties/role"},
+    {"type": "Control", "scope": "#/properties/newsletter"}
+  ]
+}
+`,
+	data: `{
+  "name": "Jane Doe",
+  "role": "editor"
+}
+`,
+}
+
+// Playground is a page to write forms on: editors for schema, UI schema and
+// data next to a live preview, the resolved UI tree and the result of
+// verifying the last submit of the preview. It serves its scripts and
+// stylesheets itself and works offline. Mount it below a path of its own:
+//
+//	mux.Handle("/playground/", gojsonforms.Mount("/playground", gojsonforms.NewPlayground()))
+//
+// The playground compiles whatever it is sent, so serve it to the people
+// writing forms only.
+type Playground struct {
+	basePath string
+	schema   string
+	uiSchema string
+	data     string
+	assets   http.Handler
+}
+
+// playgroundSources are the editor contents sent by the playground page.
+type playgroundSources struct {
+	Schema   string     `json:"schema"`
+	UISchema string     `json:"uischema"`
+	Data     string     `json:"data"`
+	Values   url.Values `json:"values"`
+}
+
+// playgroundResult answers the playground page.
+type playgroundResult struct {
+	HTML   string              `json:"html,omitempty"`
+	Tree   Node                `json:"tree,omitempty"`
+	Data   interface{}         `json:"data,omitempty"`
+	Errors map[string][]string `json:"errors,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
+
+// NewPlayground creates a playground starting with an example form.
+func NewPlayground() *Playground {
+	return &Playground{
+		schema:   playgroundExample.schema,
+		uiSchema: playgroundExample.uiSchema,
+		data:     playgroundExample.data,
+		assets:   http.StripPrefix("/"+appAssets, AssetHandler()),
+	}
+}
+
+// WithBasePath sets the path the playground is served at, for routers that
+// strip it without Mount.
+func (p *Playground) WithBasePath(basePath string) *Playground {
+	p.basePath = form.CleanBasePath(basePath)
+	return p
+}
+
+// WithExample replaces the example form the editors start with. The UI schema
+// and data are optional.
+func (p *Playground) WithExample(schema, uiSchema, data []byte) *Playground {
+	p.schema, p.uiSchema, p.data = string(schema), string(uiSchema), string(data)
+	return p
+}
+
+func (p *Playground) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	base, ok := BasePathFromContext(r.Context())
+	if !ok {
+		base = p.basePath
+	}
+
+	switch path := strings.Trim(r.URL.Path, "/"); {
+	case path == appAssets || strings.HasPrefix(path, appAssets+"/"):
+		p.assets.ServeHTTP(w, r)
+	case path == "":
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		var page bytes.Buffer
+		err := form.RenderPlayground(&page, form.PlaygroundPage{
+			BasePath:   base,
+			AssetsPath: appAssets,
+			Schema:     p.schema,
+			UISchema:   p.uiSchema,
+			Data:       p.data,
+		})
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page.Bytes())
+	case path == "preview" || path == "submit":
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		var sources playgroundSources
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, DefaultMaxRequestBytes)).Decode(&sources); err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p.result(base, sources, path == "submit"))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// result compiles the sources and renders the preview, after verifying the
+// submitted values for submits.
+func (p *Playground) result(base string, sources playgroundSources, submit bool) playgroundResult {
+	builder := NewBuilder().WithSchemaBytes([]byte(sources.Schema))
+	if strings.TrimSpace(sources.UISchema) != "" {
+		builder.WithUISchemaBytes([]byte(sources.UISchema))
+	}
+	if strings.TrimSpace(sources.Data) != "" {
+		builder.WithDataBytes([]byte(sources.Data))
+	}
+	f, err := builder.Compile()
+	if err != nil {
+		return playgroundResult{Error: err.Error()}
+	}
+
+	var result playgroundResult
+	var opts RenderOptions
+	if submit {
+		data, err := f.Verify(sources.Values)
+		result.Data = data
+		result.Errors = FieldErrors(err)
+		if err != nil && !errors.Is(err, ErrValidation) {
+			result.Error = err.Error()
+		}
+		opts = RenderOptions{Data: data, Errors: err}
+		if data == nil {
+			opts.Data = map[string]interface{}{}
+		}
+	}
+
+	if result.Tree, err = f.Tree(opts); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	var content, page bytes.Buffer
+	if err := f.RenderFragment(context.Background(), &content, opts); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if err := form.RenderPreview(&page, base, appAssets, content.Bytes()); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.HTML = page.String()
+	return result
+}
@@ -0,0 +1,82 @@
+package gojsonforms_test
+
+import (
+	"encoding/json"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+)
+
+func TestPlayground(t *testing.T) {
+	handler := gojsonforms.Mount("/playground", gojsonforms.NewPlayground().
+		WithExample([]byte(`{"type": "object", "required": ["name"], "properties": {"name": {"type": "string", "minLength": 3}}}`), nil, nil))
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+	result := func(w *httptest.ResponseRecorder) map[string]interface{} {
+		t.Helper()
+		var result map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("%v: %s", err, w.Body.String())
+		}
+		return result
+	}
+
+	// the page links everything below the playground, nothing external
+	w := serve(http.MethodGet, "/playground", "")
+	page := html.UnescapeString(w.Body.String())
+	for _, expected := range []string{`data-preview="/playground/preview"`, `"minLength": 3`, "/playground/_jsonforms/playground.js?v="} {
+		if !strings.Contains(page, expected) {
+			t.Errorf("expected %q in page:\n%s", expected, page)
+		}
+	}
+	if strings.Contains(page, "https://") {
+		t.Errorf("expected no external URL in page:\n%s", page)
+	}
+	if w := serve(http.MethodGet, "/playground/_jsonforms/playground.css", ""); w.Code != http.StatusOK {
+		t.Errorf("expected the stylesheet, got %d", w.Code)
+	}
+
+	sources := `"schema": "{\"properties\": {\"name\": {\"type\": \"string\", \"minLength\": 3}}}", "data": "{\"name\": \"John\"}"`
+	preview := result(serve(http.MethodPost, "/playground/preview", "{"+sources+"}"))
+	if h, _ := preview["html"].(string); !strings.Contains(h, `value="John"`) || strings.Contains(h, "https://") {
+		t.Errorf("expected an offline preview with the data, got %v", preview)
+	}
+	if tree, _ := preview["tree"].(map[string]interface{}); tree["kind"] != "Layout" {
+		t.Errorf("expected the tree, got %v", preview["tree"])
+	}
+
+	submit := result(serve(http.MethodPost, "/playground/submit", `{`+sources+`, "values": {"/name": ["Jo"]}}`))
+	if errs, _ := submit["errors"].(map[string]interface{}); errs["/name"] == nil {
+		t.Errorf("expected the error of name, got %v", submit)
+	}
+	if h, _ := submit["html"].(string); !strings.Contains(h, `value="Jo"`) {
+		t.Errorf("expected the submitted data in the preview, got %v", submit["html"])
+	}
+	submit = result(serve(http.MethodPost, "/playground/submit", `{`+sources+`, "values": {"/name": ["Jane"]}}`))
+	if data, _ := submit["data"].(map[string]interface{}); data["name"] != "Jane" || submit["errors"] != nil {
+		t.Errorf("expected the verified data, got %v", submit)
+	}
+
+	broken := result(serve(http.MethodPost, "/playground/preview", `{"schema": "{", "uischema": ""}`))
+	if broken["error"] == nil || broken["html"] != nil {
+		t.Errorf("expected the error, got %v", broken)
+	}
+
+	if w := serve(http.MethodPost, "/playground/preview", "{"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid requests, got %d", w.Code)
+	}
+	if w := serve(http.MethodGet, "/playground/preview", ""); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", w.Code)
+	}
+	if w := serve(http.MethodGet, "/playground/unknown", ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
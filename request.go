@@ -0,0 +1,72 @@
+package gojsonforms
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+
+	gabs "github.com/Jeffail/gabs/v2"
+)
+
+// DefaultMaxRequestBytes limits the size of submitted bodies, see
+// WithMaxRequestBytes.
+const DefaultMaxRequestBytes = 1 << 20
+
+// VerifyRequest reads the values submitted with r and verifies them like
+// Verify. It reads application/x-www-form-urlencoded, multipart/form-data and
+// application/json bodies; JSON objects are data, or form values if they are
+// keyed by form field names like {"/name": "John"} (htmx json-enc). Bodies
+// over the size limit are reported as ErrTooLarge, others that can't be read
+// as ErrInvalidData.
+func (f *Form) VerifyRequest(r *http.Request) (map[string]interface{}, error) {
+	values, data, err := f.read(r)
+	if data == nil {
+		return nil, err
+	}
+	return f.validate(values, data, err)
+}
+
+// read decodes the body of r into data, with the submitted form values; for
+// JSON just the reserved ones. Without data, err is why.
+func (f *Form) read(r *http.Request) (url.Values, *gabs.Container, error) {
+	limit := f.maxBytes
+	if limit <= 0 {
+		limit = DefaultMaxRequestBytes
+	}
+	r.Body = http.MaxBytesReader(nil, r.Body, limit)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, nil, readError(err)
+		}
+		data, values, err := f.form.DecodeJSON(body)
+		return values, data, err
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(limit); err != nil {
+			return nil, nil, readError(err)
+		}
+	case "application/x-www-form-urlencoded", "":
+		if err := r.ParseForm(); err != nil {
+			return nil, nil, readError(err)
+		}
+	default:
+		return nil, nil, &FormError{Stage: StageVerify, Err: fmt.Errorf("%w: unsupported content type %q", ErrInvalidData, mediaType)}
+	}
+
+	data, err := f.form.Decode(r.PostForm)
+	return r.PostForm, data, err
+}
+
+func readError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return &FormError{Stage: StageVerify, Err: fmt.Errorf("%w: over %d bytes", ErrTooLarge, tooLarge.Limit)}
+	}
+	return &FormError{Stage: StageVerify, Err: fmt.Errorf("%w: %v", ErrInvalidData, err)}
+}
@@ -0,0 +1,127 @@
+package gojsonforms_test
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	gojsonforms "github.com/TobiEiss/go-jsonforms"
+)
+
+func TestVerifyRequest(t *testing.T) {
+	f, err := gojsonforms.NewBuilder().
+		WithSchemaBytes([]byte(`{
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string"},
+				"age": {"type": "integer"},
+				"height": {"type": "number"},
+				"newsletter": {"type": "boolean"}
+			}
+		}`)).
+		WithMaxRequestBytes(512).
+		Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var multipartBody bytes.Buffer
+	mw := multipart.NewWriter(&multipartBody)
+	mw.WriteField("/name", "John")
+	mw.WriteField("/age", "42")
+	mw.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    map[string]interface{}
+		err         error
+	}{
+		{
+			name:        "urlencoded",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "%2Fname=John&%2Fage=42",
+			expected:    map[string]interface{}{"name": "John", "age": 42, "newsletter": false},
+		},
+		{
+			name:        "multipart",
+			contentType: mw.FormDataContentType(),
+			body:        multipartBody.String(),
+			expected:    map[string]interface{}{"name": "John", "age": 42, "newsletter": false},
+		},
+		{
+			name:        "json",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"name": "John", "age": 42, "height": 1.8, "_action": ""}`,
+			expected:    map[string]interface{}{"name": "John", "age": 42, "height": 1.8},
+		},
+		{
+			name:        "json-enc",
+			contentType: "application/json",
+			body:        `{"/name": "John", "/age": "42", "_csrf": "token"}`,
+			expected:    map[string]interface{}{"name": "John", "age": 42, "newsletter": false},
+		},
+		{
+			name:        "json validated",
+			contentType: "application/json",
+			body:        `{"age": 4.2}`,
+			expected:    map[string]interface{}{"age": 4.2},
+			err:         gojsonforms.ErrValidation,
+		},
+		{
+			name:        "json no object",
+			contentType: "application/json",
+			body:        `[1, 2]`,
+			err:         gojsonforms.ErrInvalidData,
+		},
+		{
+			name:        "too large",
+			contentType: "application/json",
+			body:        `{"name": "` + strings.Repeat("x", 600) + `"}`,
+			err:         gojsonforms.ErrTooLarge,
+		},
+		{
+			name:        "unsupported content type",
+			contentType: "text/plain",
+			body:        "name=John",
+			err:         gojsonforms.ErrInvalidData,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+			r.Header.Set("Content-Type", test.contentType)
+			data, err := f.VerifyRequest(r)
+			if !errors.Is(err, test.err) || (test.err == nil && err != nil) {
+				t.Fatalf("expected error %v, got %v", test.err, err)
+			}
+			if !reflect.DeepEqual(data, test.expected) && (test.expected != nil || data != nil) {
+				t.Errorf("expected %#v, got %#v", test.expected, data)
+			}
+		})
+	}
+
+	handler := gojsonforms.NewHandler(f)
+	for body, code := range map[string]int{
+		`{"name": "John"}`: http.StatusNoContent,
+		`{"name": 42}`:     http.StatusOK,
+		`{"name": "` + strings.Repeat("x", 600) + `"}`: http.StatusRequestEntityTooLarge,
+		`{`: http.StatusBadRequest,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("HX-Request", "true")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != code {
+			t.Errorf("expected %d for %.20s, got %d", code, body, w.Code)
+		}
+	}
+}
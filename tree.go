@@ -0,0 +1,27 @@
+package gojsonforms
+
+import "github.com/TobiEiss/go-jsonforms/internal/form"
+
+// The resolved UI tree a Form renders. Compile resolves schema and UI schema
+// into a tree of nodes, every render binds data to a copy of it. Templates get
+// the tree as .Tree, custom renderers get it from Form.Tree.
+type (
+	// Node is a Layout, Group, Label, Control or ArrayControl.
+	Node = form.Node
+	// Base holds the fields every node has: ID, Col, Options, Visible, ...
+	Base         = form.Base
+	Layout       = form.Layout
+	Group        = form.Group
+	Label        = form.Label
+	Control      = form.Control
+	ArrayControl = form.ArrayControl
+	Choice       = form.Choice
+	Rule         = form.Rule
+	Schema       = form.Schema
+)
+
+// Walk calls fn for node and all nodes below, depth first. Returning false
+// skips the children of a node.
+func Walk(node Node, fn func(Node) bool) {
+	form.Walk(node, fn)
+}